@@ -6,13 +6,13 @@ package sysinfo
 
 // BIOS information.
 type BIOS struct {
-	Vendor  string `json:"vendor,omitempty"`
-	Version string `json:"version,omitempty"`
-	Date    string `json:"date,omitempty"`
+	Vendor  string `json:"vendor,omitempty" msgpack:"vnd,omitempty"`
+	Version string `json:"version,omitempty" msgpack:"ver,omitempty"`
+	Date    string `json:"date,omitempty" msgpack:"date,omitempty"`
 }
 
 func (si *SysInfo) getBIOSInfo() {
-	si.BIOS.Vendor = slurpFile("/sys/class/dmi/id/bios_vendor")
-	si.BIOS.Version = slurpFile("/sys/class/dmi/id/bios_version")
-	si.BIOS.Date = slurpFile("/sys/class/dmi/id/bios_date")
+	si.BIOS.Vendor = si.slurpFile("/sys/class/dmi/id/bios_vendor")
+	si.BIOS.Version = si.slurpFile("/sys/class/dmi/id/bios_version")
+	si.BIOS.Date = si.slurpFile("/sys/class/dmi/id/bios_date")
 }
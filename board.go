@@ -4,26 +4,24 @@
 
 package sysinfo
 
-import "os"
-
 // Board information.
 type Board struct {
-	Name              string `json:"name,omitempty"`
-	Vendor            string `json:"vendor,omitempty"`
-	Version           string `json:"version,omitempty"`
-	Serial            string `json:"serial,omitempty"`
-	AssetTag          string `json:"assettag,omitempty"`
-	FirmwareInterface string `json:"firmwareInterface,omitempty"`
+	Name              string `json:"name,omitempty" msgpack:"name,omitempty"`
+	Vendor            string `json:"vendor,omitempty" msgpack:"vnd,omitempty"`
+	Version           string `json:"version,omitempty" msgpack:"ver,omitempty"`
+	Serial            string `json:"serial,omitempty" msgpack:"sn,omitempty"`
+	AssetTag          string `json:"assettag,omitempty" msgpack:"tag,omitempty"`
+	FirmwareInterface string `json:"firmwareInterface,omitempty" msgpack:"fw,omitempty"`
 }
 
 func (si *SysInfo) getBoardInfo() {
-	si.Board.Name = slurpFile("/sys/class/dmi/id/board_name")
-	si.Board.Vendor = slurpFile("/sys/class/dmi/id/board_vendor")
-	si.Board.Version = slurpFile("/sys/class/dmi/id/board_version")
-	si.Board.Serial = slurpFile("/sys/class/dmi/id/board_serial")
-	si.Board.AssetTag = slurpFile("/sys/class/dmi/id/board_asset_tag")
+	si.Board.Name = si.slurpFile("/sys/class/dmi/id/board_name")
+	si.Board.Vendor = si.slurpFile("/sys/class/dmi/id/board_vendor")
+	si.Board.Version = si.slurpFile("/sys/class/dmi/id/board_version")
+	si.Board.Serial = si.slurpFile("/sys/class/dmi/id/board_serial")
+	si.Board.AssetTag = si.slurpFile("/sys/class/dmi/id/board_asset_tag")
 	si.Board.FirmwareInterface = func() string {
-		if _, err := os.Stat("/sys/firmware/efi"); err == nil {
+		if _, err := si.stat("/sys/firmware/efi"); err == nil {
 			return "efi"
 		} else {
 			return "bios"
@@ -0,0 +1,100 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+import "reflect"
+
+// Static information, gathered once and reused by later GetSysInfo runs, with Config.CacheStatic.
+type staticCache struct {
+	sections map[string]reflect.Value // whole sections, by SysInfo field name
+	dmi      []byte                   // raw SMBIOS table, memory modules are decoded from it
+	serials  map[string]string        // storage device serial numbers, by device name
+}
+
+// InvalidateCache drops the static information cached with Config.CacheStatic, so the next GetSysInfo run gathers
+// everything afresh. Call it after a known hardware change, like a disk replacement.
+func (si *SysInfo) InvalidateCache() {
+	si.cache = nil
+}
+
+// Cache of static information, nil unless Config.CacheStatic is set.
+func (si *SysInfo) staticCache() *staticCache {
+	if !si.Config.CacheStatic {
+		return nil
+	}
+
+	if si.cache == nil {
+		si.cache = &staticCache{
+			sections: make(map[string]reflect.Value),
+			serials:  make(map[string]string),
+		}
+	}
+
+	return si.cache
+}
+
+// Wrap built-in getter of the section that never changes at runtime, with Config.CacheStatic it's run only once, the
+// section is restored from the cache afterwards.
+func static(section string, get func(si *SysInfo)) Collector {
+	return CollectorFunc(func(si *SysInfo) error {
+		c := si.staticCache()
+		if c == nil {
+			get(si)
+			return nil
+		}
+
+		// Sections hold slices, maps & pointers, so they are copied deep both ways, not to share anything with the
+		// caller, who might modify them.
+		field := reflect.ValueOf(si).Elem().FieldByName(section)
+		if v, ok := c.sections[section]; ok {
+			field.Set(deepCopy(v))
+			return nil
+		}
+
+		get(si)
+		c.sections[section] = deepCopy(field)
+
+		return nil
+	})
+}
+
+// Copy the value, and everything it refers to. Unexported fields are copied shallow, like the location of
+// time.Time, which is never modified anyway.
+func deepCopy(v reflect.Value) reflect.Value {
+	c := reflect.New(v.Type()).Elem()
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			c.Set(reflect.New(v.Type().Elem()))
+			c.Elem().Set(deepCopy(v.Elem()))
+		}
+	case reflect.Slice:
+		if !v.IsNil() {
+			c.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+			for i := 0; i < v.Len(); i++ {
+				c.Index(i).Set(deepCopy(v.Index(i)))
+			}
+		}
+	case reflect.Map:
+		if !v.IsNil() {
+			c.Set(reflect.MakeMapWithSize(v.Type(), v.Len()))
+			for _, k := range v.MapKeys() {
+				c.SetMapIndex(k, deepCopy(v.MapIndex(k)))
+			}
+		}
+	case reflect.Struct:
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+	default:
+		c.Set(v)
+	}
+
+	return c
+}
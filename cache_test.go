@@ -0,0 +1,50 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStaticCacheCopy(t *testing.T) {
+	enabled := true
+	want := CPU{
+		Model:                 "Test CPU",
+		Caches:                []CPUCache{{Level: 1, Type: "Data", Size: 48}},
+		ConfidentialCompute:   []string{"sgx:enabled"},
+		VirtualizationEnabled: &enabled,
+	}
+
+	runs := 0
+	c := static("CPU", func(si *SysInfo) {
+		runs++
+		cpu := want
+		cpu.Caches = append([]CPUCache(nil), want.Caches...)
+		cpu.ConfidentialCompute = append([]string(nil), want.ConfidentialCompute...)
+		virt := *want.VirtualizationEnabled
+		cpu.VirtualizationEnabled = &virt
+		si.CPU = cpu
+	})
+
+	si := SysInfo{Config: Config{CacheStatic: true}}
+	for i := 0; i < 3; i++ {
+		if err := c.Collect(&si); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(si.CPU, want) {
+			t.Fatalf("run %d: got %+v, want %+v", i, si.CPU, want)
+		}
+
+		// Whatever the caller does to the section, the cache must stay intact.
+		si.CPU.Caches[0].Size = 0
+		si.CPU.ConfidentialCompute[0] = "sgx:disabled"
+		*si.CPU.VirtualizationEnabled = false
+	}
+
+	if runs != 1 {
+		t.Errorf("getter ran %d times, want 1", runs)
+	}
+}
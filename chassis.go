@@ -8,19 +8,19 @@ import "strconv"
 
 // Chassis information.
 type Chassis struct {
-	Type     uint   `json:"type,omitempty"`
-	Vendor   string `json:"vendor,omitempty"`
-	Version  string `json:"version,omitempty"`
-	Serial   string `json:"serial,omitempty"`
-	AssetTag string `json:"assettag,omitempty"`
+	Type     uint   `json:"type,omitempty" msgpack:"type,omitempty"`
+	Vendor   string `json:"vendor,omitempty" msgpack:"vnd,omitempty"`
+	Version  string `json:"version,omitempty" msgpack:"ver,omitempty"`
+	Serial   string `json:"serial,omitempty" msgpack:"sn,omitempty"`
+	AssetTag string `json:"assettag,omitempty" msgpack:"tag,omitempty"`
 }
 
 func (si *SysInfo) getChassisInfo() {
-	if chtype, err := strconv.ParseUint(slurpFile("/sys/class/dmi/id/chassis_type"), 10, 64); err == nil {
+	if chtype, err := strconv.ParseUint(si.slurpFile("/sys/class/dmi/id/chassis_type"), 10, 64); err == nil {
 		si.Chassis.Type = uint(chtype)
 	}
-	si.Chassis.Vendor = slurpFile("/sys/class/dmi/id/chassis_vendor")
-	si.Chassis.Version = slurpFile("/sys/class/dmi/id/chassis_version")
-	si.Chassis.Serial = slurpFile("/sys/class/dmi/id/chassis_serial")
-	si.Chassis.AssetTag = slurpFile("/sys/class/dmi/id/chassis_asset_tag")
+	si.Chassis.Vendor = si.slurpFile("/sys/class/dmi/id/chassis_vendor")
+	si.Chassis.Version = si.slurpFile("/sys/class/dmi/id/chassis_version")
+	si.Chassis.Serial = si.slurpFile("/sys/class/dmi/id/chassis_serial")
+	si.Chassis.AssetTag = si.slurpFile("/sys/class/dmi/id/chassis_asset_tag")
 }
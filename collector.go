@@ -0,0 +1,79 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+import "sync"
+
+// Collector gathers a piece of system information into SysInfo.
+type Collector interface {
+	Collect(si *SysInfo) error
+}
+
+// CollectorFunc is an adapter to allow the use of ordinary functions as collectors.
+type CollectorFunc func(si *SysInfo) error
+
+// Collect calls f(si).
+func (f CollectorFunc) Collect(si *SysInfo) error {
+	return f(si)
+}
+
+// Wrap built-in getter, information is gathered on the best effort basis, so they never fail.
+func builtin(get func(si *SysInfo)) Collector {
+	return CollectorFunc(func(si *SysInfo) error {
+		get(si)
+		return nil
+	})
+}
+
+var (
+	collectorsMu sync.Mutex
+
+	// Built-in collectors, in the order they depend on each other.
+	collectors = []Collector{
+		// Meta info
+		builtin((*SysInfo).getMetaInfo),
+
+		// DMI info
+		static("Product", (*SysInfo).getProductInfo),
+		static("Board", (*SysInfo).getBoardInfo),
+		static("Chassis", (*SysInfo).getChassisInfo),
+		static("BIOS", (*SysInfo).getBIOSInfo),
+
+		// SMBIOS info
+		builtin((*SysInfo).getMemoryInfo),
+
+		// Node info
+		builtin((*SysInfo).getNodeInfo), // depends on BIOS & Product info
+
+		// Hardware info
+		static("CPU", (*SysInfo).getCPUInfo), // depends on Node info
+		builtin((*SysInfo).getCPURuntimeInfo),
+		builtin((*SysInfo).getStorageInfo),
+		builtin((*SysInfo).getNetworkInfo),
+		builtin((*SysInfo).getThermalInfo),
+		builtin((*SysInfo).getCStatesInfo),
+
+		// Software info
+		builtin((*SysInfo).getOSInfo),
+		builtin((*SysInfo).getKernelInfo),
+		builtin((*SysInfo).getRoutingInfo),
+	}
+)
+
+// Register adds the collector to the ones run by GetSysInfo. Custom collectors run after the built-in ones, in the
+// order they were registered, so they can build upon already gathered information.
+func Register(c Collector) {
+	collectorsMu.Lock()
+	defer collectorsMu.Unlock()
+
+	collectors = append(collectors, c)
+}
+
+func registeredCollectors() []Collector {
+	collectorsMu.Lock()
+	defer collectorsMu.Unlock()
+
+	return append([]Collector(nil), collectors...)
+}
@@ -7,22 +7,44 @@ package sysinfo
 import (
 	"bufio"
 	"fmt"
-	"os"
+	"path"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 )
 
 // CPU information.
 type CPU struct {
-	Vendor  string `json:"vendor,omitempty"`
-	Model   string `json:"model,omitempty"`
-	Speed   uint   `json:"speed,omitempty"`   // CPU clock rate in MHz
-	Cache   uint   `json:"cache,omitempty"`   // CPU cache size in KB
-	Cpus    uint   `json:"cpus,omitempty"`    // number of physical CPUs
-	Cores   uint   `json:"cores,omitempty"`   // number of physical CPU cores
-	Threads uint   `json:"threads,omitempty"` // number of logical (HT) CPU cores
+	Vendor                string     `json:"vendor,omitempty" msgpack:"vnd,omitempty"`
+	Model                 string     `json:"model,omitempty" msgpack:"model,omitempty"`
+	Family                uint       `json:"family,omitempty" msgpack:"fam,omitempty"`
+	ModelNumber           uint       `json:"modelnumber,omitempty" msgpack:"modn,omitempty"`
+	Stepping              uint       `json:"stepping,omitempty" msgpack:"step,omitempty"`
+	Speed                 uint       `json:"speed,omitempty" msgpack:"mhz,omitempty"`    // CPU clock rate in MHz
+	ClockMHz              float64    `json:"clockmhz,omitempty" msgpack:"clk,omitempty"` // clock of the first CPU, as /proc/cpuinfo tells, when the CPU info was collected
+	BogoMIPS              float64    `json:"bogomips,omitempty" msgpack:"bogo,omitempty"`
+	Cache                 uint       `json:"cache,omitempty" msgpack:"cache,omitempty"`                // CPU cache size in KB
+	CacheLineSize         uint       `json:"cachelinesize,omitempty" msgpack:"cline,omitempty"`        // cache line size in bytes
+	Caches                []CPUCache `json:"caches,omitempty" msgpack:"caches,omitempty"`              // sorted by level
+	Cpus                  uint       `json:"cpus,omitempty" msgpack:"cpus,omitempty"`                  // number of physical CPUs
+	Cores                 uint       `json:"cores,omitempty" msgpack:"cores,omitempty"`                // number of physical CPU cores
+	Threads               uint       `json:"threads,omitempty" msgpack:"thr,omitempty"`                // number of logical (HT) CPU cores
+	OnlineCPUs            uint       `json:"onlinecpus,omitempty" msgpack:"onl,omitempty"`             // number of logical CPUs online
+	PossibleCPUs          uint       `json:"possiblecpus,omitempty" msgpack:"pos,omitempty"`           // number of logical CPUs that can be brought online
+	ConfidentialCompute   []string   `json:"confidentialcompute,omitempty" msgpack:"cc,omitempty"`     // like sgx:enabled, sev:disabled, tdx_guest
+	BoostEnabled          *bool      `json:"boostenabled,omitempty" msgpack:"boost,omitempty"`         // turbo/boost, where cpufreq driver tells
+	VirtualizationEnabled *bool      `json:"virtualizationenabled,omitempty" msgpack:"virt,omitempty"` // VT-x/AMD-V, false when disabled in firmware
+}
+
+// CPUCache information, one entry per cache level and type.
+type CPUCache struct {
+	Level               uint   `json:"level,omitempty" msgpack:"lvl,omitempty"`
+	Type                string `json:"type,omitempty" msgpack:"type,omitempty"`     // Data, Instruction or Unified
+	Size                uint   `json:"size,omitempty" msgpack:"size,omitempty"`     // cache size in KB
+	LineSize            uint   `json:"linesize,omitempty" msgpack:"line,omitempty"` // coherency line size in bytes
+	WaysOfAssociativity uint   `json:"waysofassociativity,omitempty" msgpack:"ways,omitempty"`
 }
 
 var (
@@ -31,10 +53,117 @@ var (
 	reCacheSize  = regexp.MustCompile(`^(\d+) KB$`)
 )
 
+// Count CPUs in the list, like 0-3,6.
+func countCPUList(list string) (count uint) {
+	for _, r := range strings.Split(list, ",") {
+		bounds := strings.SplitN(r, "-", 2)
+		first, err := strconv.ParseUint(bounds[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = strconv.ParseUint(bounds[1], 10, 64); err != nil || last < first {
+				continue
+			}
+		}
+		count += uint(last - first + 1)
+	}
+
+	return
+}
+
+// Confidential computing CPU flags, and where to tell whether they're enabled, for the ones it can be told.
+var ccFeatures = []struct {
+	flag, enabled string
+}{
+	{"sgx", "/dev/sgx_enclave"},
+	{"sev", "/sys/module/kvm_amd/parameters/sev"},
+	{"sev_es", "/sys/module/kvm_amd/parameters/sev_es"},
+	{"sev_snp", "/sys/module/kvm_amd/parameters/sev_snp"},
+	{"tdx_host_platform", "/sys/module/kvm_intel/parameters/tdx"},
+	{"tdx_guest", ""},
+}
+
+// SGX is enabled when the kernel driver created its device, SEV & TDX when KVM module parameters enable them.
+func (si *SysInfo) getConfidentialCompute(flags map[string]bool) []string {
+	var features []string
+	for _, cc := range ccFeatures {
+		if !flags[cc.flag] {
+			continue
+		}
+
+		feature := cc.flag
+		switch {
+		case cc.enabled == "":
+			// Nothing to tell, like running as TDX guest, which is just enabled.
+		case strings.HasPrefix(cc.enabled, "/dev/"):
+			if _, err := si.stat(cc.enabled); err == nil {
+				feature += ":enabled"
+			} else {
+				feature += ":disabled"
+			}
+		default:
+			switch si.slurpFile(cc.enabled) {
+			case "Y", "1":
+				feature += ":enabled"
+			case "N", "0":
+				feature += ":disabled"
+			}
+		}
+		features = append(features, feature)
+	}
+
+	return features
+}
+
+// Kernel drops vmx & svm flags when firmware disabled the extensions, yet CPUID still tells the CPU supports them.
+// Nothing can be told about CPUs that don't support them at all, or don't support CPUID.
+func getVirtualizationEnabled(flags map[string]bool) *bool {
+	enabled := flags["vmx"] || flags["svm"]
+	if !enabled && !hasVirtualizationExtensions() {
+		return nil
+	}
+
+	return &enabled
+}
+
+// Caches of the first CPU, as the others are just the same, save for the hybrid ones.
+func (si *SysInfo) getCPUCaches() {
+	si.CPU.Caches, si.CPU.CacheLineSize = nil, 0
+
+	dirs, _ := si.glob("/sys/devices/system/cpu/cpu0/cache/index[0-9]*")
+	for _, dir := range dirs {
+		number := func(name string) uint {
+			n, _ := strconv.ParseUint(strings.TrimSuffix(si.slurpFile(path.Join(dir, name)), "K"), 10, 64)
+			return uint(n)
+		}
+
+		cache := CPUCache{
+			Level:               number("level"),
+			Type:                si.slurpFile(path.Join(dir, "type")),
+			Size:                number("size"),
+			LineSize:            number("coherency_line_size"),
+			WaysOfAssociativity: number("ways_of_associativity"),
+		}
+		if cache.Level == 0 {
+			continue
+		}
+		si.CPU.Caches = append(si.CPU.Caches, cache)
+	}
+
+	// Line size is the same for all levels, pretty much everywhere.
+	if len(si.CPU.Caches) > 0 {
+		si.CPU.CacheLineSize = si.CPU.Caches[0].LineSize
+	}
+	sort.SliceStable(si.CPU.Caches, func(i, j int) bool { return si.CPU.Caches[i].Level < si.CPU.Caches[j].Level })
+}
+
 func (si *SysInfo) getCPUInfo() {
 	si.CPU.Threads = uint(runtime.NumCPU())
+	si.getCPUCaches()
 
-	f, err := os.Open("/proc/cpuinfo")
+	f, err := si.open("/proc/cpuinfo")
 	if err != nil {
 		return
 	}
@@ -44,11 +173,45 @@ func (si *SysInfo) getCPUInfo() {
 	core := make(map[string]bool)
 
 	var cpuID string
+	var processors int
+
+	// Numbers identifying the model are taken from the first processor only, as 0 is a valid stepping.
+	number := func(value string, field *uint) {
+		if n, err := strconv.ParseUint(value, 10, 64); err == nil && processors == 1 {
+			*field = uint(n)
+		}
+	}
 
 	s := bufio.NewScanner(f)
 	for s.Scan() {
 		if sl := reTwoColumns.Split(s.Text(), 2); sl != nil {
 			switch sl[0] {
+			case "processor":
+				processors++
+			case "cpu family":
+				number(sl[1], &si.CPU.Family)
+			case "model":
+				number(sl[1], &si.CPU.ModelNumber)
+			case "stepping":
+				number(sl[1], &si.CPU.Stepping)
+			case "flags":
+				if processors == 1 {
+					flags := make(map[string]bool)
+					for _, flag := range strings.Fields(sl[1]) {
+						flags[flag] = true
+					}
+					si.CPU.ConfidentialCompute = si.getConfidentialCompute(flags)
+					si.CPU.VirtualizationEnabled = getVirtualizationEnabled(flags)
+				}
+			case "cpu MHz":
+				// x86 only. Often the only frequency known on VMs, with no cpufreq driver.
+				if mhz, err := strconv.ParseFloat(sl[1], 64); err == nil && processors == 1 {
+					si.CPU.ClockMHz = mhz
+				}
+			case "bogomips", "BogoMIPS":
+				if bogomips, err := strconv.ParseFloat(sl[1], 64); err == nil && si.CPU.BogoMIPS == 0 {
+					si.CPU.BogoMIPS = bogomips
+				}
 			case "physical id":
 				cpuID = sl[1]
 				cpu[cpuID] = true
@@ -0,0 +1,27 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+// Read turbo/boost state, acpi-cpufreq & amd-pstate have direct boost switch, intel_pstate the inverted no_turbo one.
+func (si *SysInfo) getBoost() *bool {
+	var enabled bool
+	if boost := si.slurpFile("/sys/devices/system/cpu/cpufreq/boost"); boost != "" {
+		enabled = boost == "1"
+	} else if noTurbo := si.slurpFile("/sys/devices/system/cpu/intel_pstate/no_turbo"); noTurbo != "" {
+		enabled = noTurbo == "0"
+	} else {
+		return nil
+	}
+
+	return &enabled
+}
+
+// Frequency settings change at runtime, and CPUs go online & offline, so unlike the rest of the CPU info these
+// are never cached.
+func (si *SysInfo) getCPURuntimeInfo() {
+	si.CPU.BoostEnabled = si.getBoost()
+	si.CPU.OnlineCPUs = countCPUList(si.slurpFile("/sys/devices/system/cpu/online"))
+	si.CPU.PossibleCPUs = countCPUList(si.slurpFile("/sys/devices/system/cpu/possible"))
+}
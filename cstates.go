@@ -0,0 +1,99 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+import (
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CPUIdle lists idle states (C-states) of the logical CPU.
+type CPUIdle struct {
+	CPU    uint     `json:"cpu" msgpack:"cpu"`
+	States []CState `json:"states,omitempty" msgpack:"st,omitempty"`
+}
+
+// CState information, with cumulative counters since boot.
+type CState struct {
+	Name     string `json:"name,omitempty" msgpack:"name,omitempty"`   // like POLL, C1, C1E, C6
+	Latency  uint   `json:"latency,omitempty" msgpack:"lat,omitempty"` // exit latency in µs
+	Usage    uint64 `json:"usage,omitempty" msgpack:"use,omitempty"`   // number of times the state was entered
+	Time     uint64 `json:"time,omitempty" msgpack:"time,omitempty"`   // residency in µs
+	Disabled bool   `json:"disabled,omitempty" msgpack:"dis,omitempty"`
+}
+
+// Order C-states by their index, deeper states come later.
+type byIndex struct {
+	states  []CState
+	indexes []uint64
+}
+
+func (b byIndex) Len() int           { return len(b.states) }
+func (b byIndex) Less(i, j int) bool { return b.indexes[i] < b.indexes[j] }
+func (b byIndex) Swap(i, j int) {
+	b.states[i], b.states[j] = b.states[j], b.states[i]
+	b.indexes[i], b.indexes[j] = b.indexes[j], b.indexes[i]
+}
+
+func (si *SysInfo) getCStatesInfo() {
+	sysCPU := "/sys/devices/system/cpu"
+	cpus, err := si.readDir(sysCPU)
+	if err != nil {
+		return
+	}
+
+	si.CStates = nil
+	for _, link := range cpus {
+		if !strings.HasPrefix(link.Name(), "cpu") {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimPrefix(link.Name(), "cpu"), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		// Absent with no cpuidle driver, like on most VMs.
+		cpuidle := path.Join(sysCPU, link.Name(), "cpuidle")
+		states, err := si.readDir(cpuidle)
+		if err != nil {
+			continue
+		}
+
+		idle := CPUIdle{CPU: uint(id)}
+		var indexes []uint64
+		for _, state := range states {
+			index, err := strconv.ParseUint(strings.TrimPrefix(state.Name(), "state"), 10, 64)
+			if !strings.HasPrefix(state.Name(), "state") || err != nil {
+				continue
+			}
+
+			fullpath := path.Join(cpuidle, state.Name())
+			cstate := CState{
+				Name:     si.slurpFile(path.Join(fullpath, "name")),
+				Disabled: si.slurpFile(path.Join(fullpath, "disable")) == "1",
+			}
+			if latency, err := strconv.ParseUint(si.slurpFile(path.Join(fullpath, "latency")), 10, 64); err == nil {
+				cstate.Latency = uint(latency)
+			}
+			cstate.Usage, _ = strconv.ParseUint(si.slurpFile(path.Join(fullpath, "usage")), 10, 64)
+			cstate.Time, _ = strconv.ParseUint(si.slurpFile(path.Join(fullpath, "time")), 10, 64)
+
+			idle.States = append(idle.States, cstate)
+			indexes = append(indexes, index)
+		}
+
+		// Directory order is lexical, state10 comes before state2.
+		sort.Sort(byIndex{idle.States, indexes})
+
+		if len(idle.States) > 0 {
+			si.CStates = append(si.CStates, idle)
+		}
+	}
+
+	// Directory order is lexical, cpu10 comes before cpu2.
+	sort.Slice(si.CStates, func(i, j int) bool { return si.CStates[i].CPU < si.CStates[j].CPU })
+}
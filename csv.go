@@ -0,0 +1,73 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"strings"
+)
+
+// Columns of the storage inventory, the same for device and partition rows.
+var storageCSVHeader = []string{"type", "device", "model", "serial", "transport", "partition", "mountPoint", "size",
+	"available"}
+
+// StorageCSV returns the storage inventory in CSV format, with the header line first. Every device gets a row, followed
+// by a row for every partition, then the devices stacked on top, if the tree was built. Sizes are in MB, as in the
+// structs.
+func (si SysInfo) StorageCSV() []byte {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	_ = w.Write(storageCSVHeader)
+	for _, device := range si.Storage {
+		writeStorageCSV(w, device)
+	}
+
+	w.Flush()
+	if w.Error() != nil {
+		return nil
+	}
+
+	return buf.Bytes()
+}
+
+func writeStorageCSV(w *csv.Writer, device StorageDevice) {
+	_ = w.Write([]string{"device", device.Name, device.Model, device.Serial, storageTransport(device), "", "",
+		formatSize(device.Size), ""})
+	for _, part := range device.Partitions {
+		_ = w.Write([]string{"partition", device.Name, "", "", "", part.Name, part.MountPoint, formatSize(part.Size),
+			formatSize(part.AvailableSize)})
+	}
+	for _, child := range device.Children {
+		writeStorageCSV(w, child)
+	}
+}
+
+// Unknown sizes are left empty, not to pass for empty disks.
+func formatSize(size uint) string {
+	if size == 0 {
+		return ""
+	}
+
+	return strconv.FormatUint(uint64(size), 10)
+}
+
+// Bus the device is attached to, as told by its path in sysfs. Virtual devices have none.
+func storageTransport(device StorageDevice) string {
+	for _, transport := range []string{"usb", "ata", "nvme", "virtio", "mmc"} {
+		for _, elem := range strings.Split(device.SysPath, "/") {
+			if strings.HasPrefix(elem, transport) {
+				return transport
+			}
+		}
+	}
+	if strings.Contains(device.SysPath, "/host") {
+		return "scsi"
+	}
+
+	return ""
+}
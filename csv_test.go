@@ -0,0 +1,32 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+import "testing"
+
+func TestStorageCSV(t *testing.T) {
+	si := SysInfo{Storage: []StorageDevice{
+		{
+			Name:    "sda",
+			Model:   "DISK, BIG",
+			Serial:  "S123",
+			Size:    500107,
+			SysPath: "/sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda",
+			Partitions: []Partition{
+				{Name: "sda1", MountPoint: "/", Size: 500000, AvailableSize: 1234},
+			},
+		},
+		{Name: "overlay", Partitions: []Partition{{Name: "/var/lib/docker/overlay2/x/merged"}}},
+	}}
+
+	want := "type,device,model,serial,transport,partition,mountPoint,size,available\n" +
+		"device,sda,\"DISK, BIG\",S123,ata,,,500107,\n" +
+		"partition,sda,,,,sda1,/,500000,1234\n" +
+		"device,overlay,,,,,,,\n" +
+		"partition,overlay,,,,/var/lib/docker/overlay2/x/merged,,,\n"
+	if got := string(si.StorageCSV()); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package sysinfo
+
+import (
+	"encoding/binary"
+	"runtime"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Commands & string sets, from /usr/include/linux/ethtool.h
+const (
+	ethtoolGStrings  = 0x1b
+	ethtoolGSSetInfo = 0x37
+	ethtoolGFeatures = 0x3a
+	ethSSFeatures    = 4
+
+	ethGStringLen = 32
+)
+
+// Offload features reported, by their kernel names.
+var offloadFeatures = map[string]bool{
+	"rx-checksum":             true,
+	"tx-checksum-ipv4":        true,
+	"tx-checksum-ipv6":        true,
+	"tx-checksum-ip-generic":  true,
+	"tx-scatter-gather":       true,
+	"tx-tcp-segmentation":     true,
+	"tx-tcp6-segmentation":    true,
+	"tx-udp-segmentation":     true,
+	"tx-generic-segmentation": true,
+	"rx-gro":                  true,
+	"rx-gro-hw":               true,
+	"rx-lro":                  true,
+	"rx-vlan-hw-parse":        true,
+	"tx-vlan-hw-insert":       true,
+	"rx-hashing":              true,
+	"rx-ntuple-filter":        true,
+}
+
+// Issue SIOCETHTOOL ioctl, with data pointing to the command buffer.
+func ethtoolIoctl(fd int, name string, data []byte) error {
+	// struct ifreq from /usr/include/linux/if.h
+	var ifr struct {
+		Name [unix.IFNAMSIZ]byte
+		Data uintptr
+		_    [16]byte // rest of the union
+	}
+	copy(ifr.Name[:], name)
+	ifr.Data = uintptr(unsafe.Pointer(&data[0]))
+
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), unix.SIOCETHTOOL, uintptr(unsafe.Pointer(&ifr)))
+	runtime.KeepAlive(data)
+	if errno != 0 {
+		return errno
+	}
+
+	return nil
+}
+
+// Read the state of offload features, through ethtool features API: count & names of the features first, then
+// their state, in bitmap blocks of 32 features.
+func getOffloads(name string) map[string]bool {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM, unix.IPPROTO_IP)
+	if err != nil {
+		return nil
+	}
+	defer unix.Close(fd)
+
+	le := binary.LittleEndian
+
+	// struct ethtool_sset_info, with room for one count
+	sset := make([]byte, 20)
+	le.PutUint32(sset[0:], ethtoolGSSetInfo)
+	le.PutUint64(sset[8:], 1<<ethSSFeatures)
+	if ethtoolIoctl(fd, name, sset) != nil || le.Uint64(sset[8:]) == 0 {
+		return nil
+	}
+	count := int(le.Uint32(sset[16:]))
+
+	// struct ethtool_gstrings
+	strs := make([]byte, 12+count*ethGStringLen)
+	le.PutUint32(strs[0:], ethtoolGStrings)
+	le.PutUint32(strs[4:], ethSSFeatures)
+	le.PutUint32(strs[8:], uint32(count))
+	if ethtoolIoctl(fd, name, strs) != nil {
+		return nil
+	}
+
+	// struct ethtool_gfeatures, blocks of available, requested, active & never changed bitmaps
+	blocks := (count + 31) / 32
+	feats := make([]byte, 8+blocks*16)
+	le.PutUint32(feats[0:], ethtoolGFeatures)
+	le.PutUint32(feats[4:], uint32(blocks))
+	if ethtoolIoctl(fd, name, feats) != nil {
+		return nil
+	}
+
+	offloads := make(map[string]bool)
+	for i := 0; i < count; i++ {
+		feature := strings.TrimRight(string(strs[12+i*ethGStringLen:12+(i+1)*ethGStringLen]), "\000")
+		if !offloadFeatures[feature] {
+			continue
+		}
+
+		block := feats[8+i/32*16:]
+		available, active := le.Uint32(block[0:]), le.Uint32(block[8:])
+		bit := uint32(1) << uint(i%32)
+
+		// Features the device can do, or does, fixed ones are never available for changing.
+		if available&bit != 0 || active&bit != 0 {
+			offloads[feature] = active&bit != 0
+		}
+	}
+
+	if len(offloads) == 0 {
+		return nil
+	}
+
+	return offloads
+}
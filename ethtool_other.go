@@ -0,0 +1,12 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+//go:build !linux
+// +build !linux
+
+package sysinfo
+
+func getOffloads(name string) map[string]bool {
+	return nil
+}
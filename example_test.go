@@ -5,9 +5,11 @@
 package sysinfo_test
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"testing"
 
 	"github.com/zcalusic/sysinfo"
@@ -28,3 +30,41 @@ func Test(t *testing.T) {
 
 	fmt.Println(string(data))
 }
+
+func TestLoad(t *testing.T) {
+	var si sysinfo.SysInfo
+	si.GetSysInfo()
+
+	data, err := json.Marshal(&si)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := sysinfo.Load(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := json.Marshal(&loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, reloaded) {
+		t.Errorf("snapshot changed on the round trip:\n%s\n%s", data, reloaded)
+	}
+	if !loaded.Equal(si) {
+		t.Errorf("snapshot read back doesn't equal the system it was taken from")
+	}
+	loaded.Kernel.Processes++
+	if !loaded.Equal(si) {
+		t.Errorf("volatile fields compared")
+	}
+	loaded.Kernel.Release += "-changed"
+	if loaded.Equal(si) {
+		t.Errorf("changed kernel release not told apart")
+	}
+
+	// Fields unknown to this version are ignored.
+	if _, err := sysinfo.Load(strings.NewReader(`{"sysinfo":{"schemaversion":99},"future":{"field":1}}`)); err != nil {
+		t.Errorf("Load() with unknown fields: %v", err)
+	}
+}
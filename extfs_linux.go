@@ -0,0 +1,190 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package sysinfo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Superblock offsets & flags, from fs/ext4/ext4.h
+const (
+	extSuperblockOffset = 1024
+	extSuperblockSize   = 1024
+	extMagic            = 0xef53
+
+	extValidFS          = 0x0001
+	extErrorFS          = 0x0002
+	extCompatHasJournal = 0x0004
+)
+
+// Feature names by flag, as e2fsprogs knows them (lib/e2p/feature.c).
+var (
+	extCompatFeatures = map[uint32]string{
+		0x0001: "dir_prealloc",
+		0x0002: "imagic_inodes",
+		0x0004: "has_journal",
+		0x0008: "ext_attr",
+		0x0010: "resize_inode",
+		0x0020: "dir_index",
+		0x0040: "lazy_bg",
+		0x0080: "snapshot_bitmap",
+		0x0200: "sparse_super2",
+		0x0400: "fast_commit",
+		0x0800: "stable_inodes",
+		0x1000: "orphan_file",
+	}
+	extIncompatFeatures = map[uint32]string{
+		0x00001: "compression",
+		0x00002: "filetype",
+		0x00004: "needs_recovery",
+		0x00008: "journal_dev",
+		0x00010: "meta_bg",
+		0x00040: "extent",
+		0x00080: "64bit",
+		0x00100: "mmp",
+		0x00200: "flex_bg",
+		0x00400: "ea_inode",
+		0x01000: "dirdata",
+		0x02000: "metadata_csum_seed",
+		0x04000: "large_dir",
+		0x08000: "inline_data",
+		0x10000: "encrypt",
+		0x20000: "casefold",
+	}
+	extROCompatFeatures = map[uint32]string{
+		0x00001: "sparse_super",
+		0x00002: "large_file",
+		0x00008: "huge_file",
+		0x00010: "uninit_bg",
+		0x00020: "dir_nlink",
+		0x00040: "extra_isize",
+		0x00080: "snapshot",
+		0x00100: "quota",
+		0x00200: "bigalloc",
+		0x00400: "metadata_csum",
+		0x00800: "replica",
+		0x01000: "read-only",
+		0x02000: "project",
+		0x04000: "shared_blocks",
+		0x08000: "verity",
+		0x10000: "orphan_present",
+	}
+)
+
+// Ext2/3/4 superblock, the parts we care about.
+type extSuperblock struct {
+	mtime           time.Time
+	mntCount        uint16
+	maxMntCount     int16
+	state           uint16
+	lastCheck       time.Time
+	checkInterval   time.Duration
+	featureCompat   uint32
+	featureIncompat uint32
+	featureROCompat uint32
+}
+
+func isExtFS(fsType string) bool {
+	return fsType == "ext2" || fsType == "ext3" || fsType == "ext4"
+}
+
+// Read ext filesystem superblock directly from the block device, requires superuser privileges.
+func readExtSuperblock(devpath string) (*extSuperblock, error) {
+	f, err := os.Open(devpath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data := make([]byte, extSuperblockSize)
+	if _, err = f.ReadAt(data, extSuperblockOffset); err != nil {
+		return nil, err
+	}
+
+	le := binary.LittleEndian
+	if le.Uint16(data[0x38:]) != extMagic {
+		return nil, os.ErrInvalid
+	}
+
+	// Upper 8 bits of the 40-bit timestamps live at the end of the superblock.
+	timestamp := func(lo, hi int) time.Time {
+		t := int64(le.Uint32(data[lo:])) | int64(data[hi])<<32
+		if t == 0 {
+			return time.Time{}
+		}
+		return time.Unix(t, 0)
+	}
+
+	return &extSuperblock{
+		mtime:           timestamp(0x2c, 0x275),
+		mntCount:        le.Uint16(data[0x34:]),
+		maxMntCount:     int16(le.Uint16(data[0x36:])),
+		state:           le.Uint16(data[0x3a:]),
+		lastCheck:       timestamp(0x40, 0x277),
+		checkInterval:   time.Duration(le.Uint32(data[0x44:])) * time.Second,
+		featureCompat:   le.Uint32(data[0x5c:]),
+		featureIncompat: le.Uint32(data[0x60:]),
+		featureROCompat: le.Uint32(data[0x64:]),
+	}, nil
+}
+
+// Decide whether the filesystem should be checked, the same way e2fsck -p does it: errors were detected, it wasn't
+// cleanly unmounted, or the maximal mount count or check interval is reached.
+func (sb *extSuperblock) needsCheck() bool {
+	if sb.state&extErrorFS != 0 {
+		return true
+	}
+
+	// Kernel clears the valid flag while filesystem without journal is mounted, so it only tells something on
+	// journaled filesystems.
+	if sb.state&extValidFS == 0 && sb.featureCompat&extCompatHasJournal != 0 {
+		return true
+	}
+
+	if sb.maxMntCount > 0 && sb.mntCount >= uint16(sb.maxMntCount) {
+		return true
+	}
+
+	if sb.checkInterval > 0 && !sb.lastCheck.IsZero() && time.Since(sb.lastCheck) > sb.checkInterval {
+		return true
+	}
+
+	return false
+}
+
+// Names of the enabled features, compatible, incompatible, then read-only compatible ones, by flag value. Unknown
+// flags are reported the way e2fsprogs does, like FEATURE_I31.
+func (sb *extSuperblock) features() []string {
+	var features []string
+	for _, set := range []struct {
+		prefix string
+		flags  uint32
+		names  map[uint32]string
+	}{
+		{"C", sb.featureCompat, extCompatFeatures},
+		{"I", sb.featureIncompat, extIncompatFeatures},
+		{"R", sb.featureROCompat, extROCompatFeatures},
+	} {
+		for bit := uint(0); bit < 32; bit++ {
+			flag := uint32(1) << bit
+			if set.flags&flag == 0 {
+				continue
+			}
+			if name, ok := set.names[flag]; ok {
+				features = append(features, name)
+			} else {
+				features = append(features, fmt.Sprintf("FEATURE_%s%d", set.prefix, bit))
+			}
+		}
+	}
+
+	return features
+}
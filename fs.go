@@ -0,0 +1,159 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+import (
+	"errors"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// FS is the filesystem procfs, sysfs and the rest of the system files are read from, set with Config.FS to gather
+// information from a copy of them, or to mock them. Names are slash separated paths without the leading slash, the
+// way fs.FS wants them, so sys/block/sda stands for /sys/block/sda.
+type FS interface {
+	fs.FS
+
+	// Readlink returns the destination of the named symbolic link, like os.Readlink.
+	Readlink(name string) (string, error)
+
+	// Statfs returns usage of the filesystem mounted at the named directory, like statfs(2).
+	Statfs(name string) (FSUsage, error)
+}
+
+// FSUsage of a mounted filesystem, in bytes.
+type FSUsage struct {
+	Size      uint64
+	Free      uint64 // free space, the space reserved for the superuser included
+	Available uint64 // free space available to unprivileged users
+}
+
+var errTooManyLinks = errors.New("too many links")
+
+// Turn absolute path into the fs.FS name.
+func fsName(name string) string {
+	if name = strings.TrimPrefix(path.Clean(name), "/"); name == "" {
+		return "."
+	}
+
+	return name
+}
+
+// All the file access below goes straight to the OS, unless Config.FS is set.
+
+func (si *SysInfo) open(name string) (fs.File, error) {
+	if si.Config.FS == nil {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		return f, nil
+	}
+
+	return si.Config.FS.Open(fsName(name))
+}
+
+func (si *SysInfo) readFile(name string) ([]byte, error) {
+	if si.Config.FS == nil {
+		return ioutil.ReadFile(name)
+	}
+
+	return fs.ReadFile(si.Config.FS, fsName(name))
+}
+
+// Entries are sorted by name.
+func (si *SysInfo) readDir(name string) ([]fs.DirEntry, error) {
+	if si.Config.FS == nil {
+		return os.ReadDir(name)
+	}
+
+	return fs.ReadDir(si.Config.FS, fsName(name))
+}
+
+func (si *SysInfo) readlink(name string) (string, error) {
+	if si.Config.FS == nil {
+		return os.Readlink(name)
+	}
+
+	return si.Config.FS.Readlink(fsName(name))
+}
+
+func (si *SysInfo) stat(name string) (fs.FileInfo, error) {
+	if si.Config.FS == nil {
+		return os.Stat(name)
+	}
+
+	return fs.Stat(si.Config.FS, fsName(name))
+}
+
+// Absolute pattern gives absolute matches.
+func (si *SysInfo) glob(pattern string) ([]string, error) {
+	if si.Config.FS == nil {
+		return filepath.Glob(pattern)
+	}
+
+	matches, err := fs.Glob(si.Config.FS, fsName(pattern))
+	for i := range matches {
+		matches[i] = "/" + matches[i]
+	}
+
+	return matches, err
+}
+
+func (si *SysInfo) statfs(name string) (FSUsage, error) {
+	if si.Config.FS == nil {
+		return statfs(name)
+	}
+
+	return si.Config.FS.Statfs(fsName(name))
+}
+
+// Resolve all the symbolic links in the absolute path, like filepath.EvalSymlinks does it, one path element at a time,
+// as fs.FS knows nothing about the links.
+func (si *SysInfo) evalSymlinks(name string) (string, error) {
+	if si.Config.FS == nil {
+		return filepath.EvalSymlinks(name)
+	}
+
+	resolved := "/"
+	rest := strings.Split(name, "/")
+	for links := 0; len(rest) > 0; {
+		elem := rest[0]
+		rest = rest[1:]
+
+		switch elem {
+		case "", ".":
+			continue
+		case "..":
+			resolved = path.Dir(resolved)
+			continue
+		}
+
+		next := path.Join(resolved, elem)
+		target, err := si.Config.FS.Readlink(fsName(next))
+		if err != nil {
+			// Not a link, as long as it exists.
+			if _, err := fs.Stat(si.Config.FS, fsName(next)); err != nil {
+				return "", err
+			}
+			resolved = next
+			continue
+		}
+
+		if links++; links > 255 {
+			return "", &fs.PathError{Op: "evalsymlinks", Path: name, Err: errTooManyLinks}
+		}
+		if path.IsAbs(target) {
+			resolved = "/"
+		}
+		rest = append(strings.Split(target, "/"), rest...)
+	}
+
+	return resolved, nil
+}
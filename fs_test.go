@@ -0,0 +1,112 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// Fake filesystem, with symbolic links resolved the way the kernel does it.
+type testFS struct {
+	files fstest.MapFS
+	links map[string]string
+}
+
+func newTestFS(files map[string]string, links map[string]string) *testFS {
+	t := &testFS{files: make(fstest.MapFS), links: links}
+	for name, data := range files {
+		t.files[name] = &fstest.MapFile{Data: []byte(data)}
+	}
+	// Only for the links to show up in the directory listings.
+	for name := range links {
+		t.files[name] = &fstest.MapFile{Mode: fs.ModeSymlink | 0777}
+	}
+
+	return t
+}
+
+// Resolve the links in the name, the last element too, if asked to.
+func (t *testFS) resolve(name string, last bool) string {
+	var resolved string
+	rest := strings.Split(name, "/")
+	for links := 0; len(rest) > 0; {
+		elem := rest[0]
+		rest = rest[1:]
+
+		switch elem {
+		case "", ".":
+			continue
+		case "..":
+			if resolved = path.Dir(resolved); resolved == "." {
+				resolved = ""
+			}
+			continue
+		}
+
+		next := path.Join(resolved, elem)
+		if target, ok := t.links[next]; ok && (last || len(rest) > 0) && links < 40 {
+			links++
+			if path.IsAbs(target) {
+				resolved = ""
+			}
+			rest = append(strings.Split(target, "/"), rest...)
+			continue
+		}
+		resolved = next
+	}
+
+	if resolved == "" {
+		return "."
+	}
+
+	return resolved
+}
+
+func (t *testFS) Open(name string) (fs.File, error) {
+	return t.files.Open(t.resolve(name, true))
+}
+
+func (t *testFS) Readlink(name string) (string, error) {
+	resolved := t.resolve(name, false)
+	if target, ok := t.links[resolved]; ok {
+		return target, nil
+	}
+	if _, err := t.files.Stat(resolved); err != nil {
+		return "", err
+	}
+
+	return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+}
+
+func (t *testFS) Statfs(name string) (FSUsage, error) {
+	return FSUsage{}, &fs.PathError{Op: "statfs", Path: name, Err: fs.ErrNotExist}
+}
+
+func TestEvalSymlinks(t *testing.T) {
+	si := SysInfo{Config: Config{FS: newTestFS(
+		map[string]string{"c/d/file": "x"},
+		map[string]string{"a/rel": "../b", "b/abs": "/c/d", "b/loop": "loop"},
+	)}}
+
+	for _, tc := range []struct {
+		name, want string
+		err        bool
+	}{
+		{"/a/rel/abs/file", "/c/d/file", false},
+		{"/a/rel/abs", "/c/d", false},
+		{"/c/d/../d/file", "/c/d/file", false},
+		{"/c/d/missing", "", true},
+		{"/b/loop", "", true},
+	} {
+		got, err := si.evalSymlinks(tc.name)
+		if (err != nil) != tc.err || got != tc.want {
+			t.Errorf("evalSymlinks(%s) = %q, %v, want %q, error %v", tc.name, got, err, tc.want, tc.err)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+//go:build !windows
+// +build !windows
+
+package sysinfo
+
+import "golang.org/x/sys/unix"
+
+func statfs(name string) (FSUsage, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(name, &stat); err != nil {
+		return FSUsage{}, err
+	}
+
+	bsize := uint64(stat.Bsize)
+	return FSUsage{
+		Size:      uint64(stat.Blocks) * bsize,
+		Free:      uint64(stat.Bfree) * bsize,
+		Available: uint64(stat.Bavail) * bsize,
+	}, nil
+}
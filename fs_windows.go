@@ -0,0 +1,21 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+import "golang.org/x/sys/windows"
+
+func statfs(name string) (FSUsage, error) {
+	p, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return FSUsage{}, err
+	}
+
+	var usage FSUsage
+	if err := windows.GetDiskFreeSpaceEx(p, &usage.Available, &usage.Size, &usage.Free); err != nil {
+		return FSUsage{}, err
+	}
+
+	return usage, nil
+}
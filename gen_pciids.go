@@ -0,0 +1,45 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+//go:build ignore
+// +build ignore
+
+// gen_pciids fetches the complete PCI ID database, to be embedded into sysinfo. Run it with go generate.
+package main
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"os"
+)
+
+const pciIDsURL = "https://pci-ids.ucw.cz/v2.2/pci.ids"
+
+func main() {
+	resp, err := http.Get(pciIDsURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("%s: %s", pciIDsURL, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Don't replace the database with an error page.
+	if !bytes.Contains(data, []byte("\n10de  NVIDIA Corporation\n")) {
+		log.Fatalf("%s: not a PCI ID database", pciIDsURL)
+	}
+
+	if err := os.WriteFile("pci.ids", data, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
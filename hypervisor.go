@@ -21,12 +21,27 @@ var hvmap = map[string]string{
 	"XenVMMXenVMM": "xenhvm",
 }
 
+// DMI system vendors that only ever show up in virtual machines.
+var vmVendors = []string{"QEMU", "innotek GmbH", "VMware, Inc.", "Xen", "Parallels Software International Inc.", "bhyve"}
+
 func isHypervisorActive() bool {
 	var info [4]uint32
 	cpuid.CPUID(&info, 0x1)
 	return info[2]&(1<<31) != 0
 }
 
+// Intel VT-x (VMX) or AMD-V (SVM) hardware virtualization extensions.
+func hasVirtualizationExtensions() bool {
+	var info [4]uint32
+	cpuid.CPUID(&info, 0x1)
+	if info[2]&(1<<5) != 0 {
+		return true
+	}
+
+	cpuid.CPUID(&info, 0x80000001)
+	return info[2]&(1<<2) != 0
+}
+
 func getHypervisorCpuid(ax uint32) string {
 	var info [4]uint32
 	cpuid.CPUID(&info, ax)
@@ -35,7 +50,7 @@ func getHypervisorCpuid(ax uint32) string {
 
 func (si *SysInfo) getHypervisor() {
 	if !isHypervisorActive() {
-		if hypervisorType := slurpFile("/sys/hypervisor/type"); hypervisorType != "" {
+		if hypervisorType := si.slurpFile("/sys/hypervisor/type"); hypervisorType != "" {
 			if hypervisorType == "xen" {
 				si.Node.Hypervisor = "xenpv"
 			}
@@ -63,3 +78,31 @@ func (si *SysInfo) getHypervisor() {
 
 	si.Node.Hypervisor = "unknown"
 }
+
+func (si *SysInfo) getVirtRole() {
+	// getHypervisor() must have run first
+	if si.Node.Hypervisor != "" {
+		si.Node.VirtRole = "guest"
+		return
+	}
+
+	for _, vendor := range vmVendors {
+		if si.Product.Vendor == vendor {
+			si.Node.VirtRole = "guest"
+			return
+		}
+	}
+
+	if _, err := si.stat("/dev/kvm"); err == nil {
+		si.Node.VirtRole = "host"
+		return
+	}
+
+	// CPU is not of the system the copy of the files is from.
+	if si.Config.FS == nil && hasVirtualizationExtensions() {
+		si.Node.VirtRole = "host"
+		return
+	}
+
+	si.Node.VirtRole = "none"
+}
@@ -0,0 +1,31 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+import "testing"
+
+func TestVirtRole(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		files      map[string]string
+		hypervisor string
+		vendor     string
+		want       string
+	}{
+		{name: "host", files: map[string]string{"dev/kvm": ""}, want: "host"},
+		{name: "guest", hypervisor: "kvm", want: "guest"},
+		{name: "guest by vendor", vendor: "QEMU", want: "guest"},
+		{name: "none", want: "none"},
+	} {
+		si := SysInfo{Config: Config{FS: newTestFS(tc.files, nil)}}
+		si.Node.Hypervisor = tc.hypervisor
+		si.Product.Vendor = tc.vendor
+		si.getVirtRole()
+
+		if si.Node.VirtRole != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, si.Node.VirtRole, tc.want)
+		}
+	}
+}
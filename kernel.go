@@ -0,0 +1,30 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+// Kernel information.
+type Kernel struct {
+	Release               string            `json:"release,omitempty" msgpack:"rel,omitempty"`
+	Version               string            `json:"version,omitempty" msgpack:"ver,omitempty"`
+	Architecture          string            `json:"architecture,omitempty" msgpack:"arch,omitempty"`
+	Processes             uint              `json:"processes,omitempty" msgpack:"proc,omitempty"`
+	Threads               uint              `json:"threads,omitempty" msgpack:"thr,omitempty"`
+	Files                 uint              `json:"files,omitempty" msgpack:"fil,omitempty"`       // open file handles
+	FilesMax              uint              `json:"filesmax,omitempty" msgpack:"film,omitempty"`   // max file handles
+	Inodes                uint              `json:"inodes,omitempty" msgpack:"ino,omitempty"`      // allocated inodes
+	InodesFree            uint              `json:"inodesfree,omitempty" msgpack:"inof,omitempty"` // free allocated inodes
+	Sysctls               map[string]string `json:"sysctls,omitempty" msgpack:"sysctl,omitempty"`  // selected tunables, see Config.Sysctls
+	Tainted               uint64            `json:"tainted,omitempty" msgpack:"taint,omitempty"`   // taint flags bitmask
+	TaintReasons          []string          `json:"taintreasons,omitempty" msgpack:"taintr,omitempty"`
+	BuildDate             string            `json:"builddate,omitempty" msgpack:"bdate,omitempty"`
+	Compiler              string            `json:"compiler,omitempty" msgpack:"cc,omitempty"`          // compiler & linker the kernel was built with
+	EntropyAvailable      uint              `json:"entropyavailable,omitempty" msgpack:"ent,omitempty"` // bits of entropy in the random pool
+	EntropyPoolSize       uint              `json:"entropypoolsize,omitempty" msgpack:"entp,omitempty"`
+	Preemption            string            `json:"preemption,omitempty" msgpack:"preempt,omitempty"` // preemption model, like none, voluntary, full, rt
+	Tickless              bool              `json:"tickless,omitempty" msgpack:"nohz,omitempty"`      // timer ticks stop on idle CPUs, or full dynticks
+	HZ                    uint              `json:"hz,omitempty" msgpack:"hz,omitempty"`              // timer interrupt frequency
+	Clocksource           string            `json:"clocksource,omitempty" msgpack:"clk,omitempty"`    // like tsc, hpet, kvm-clock
+	AvailableClocksources []string          `json:"availableclocksources,omitempty" msgpack:"clks,omitempty"`
+}
@@ -2,12 +2,5 @@
 
 package sysinfo
 
-// Kernel information.
-type Kernel struct {
-	Release      string `json:"release,omitempty"`
-	Version      string `json:"version,omitempty"`
-	Architecture string `json:"architecture,omitempty"`
-}
-
 func (si *SysInfo) getKernelInfo() {
 }
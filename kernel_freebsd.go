@@ -0,0 +1,20 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+//+build freebsd
+
+package sysinfo
+
+import "golang.org/x/sys/unix"
+
+func (si *SysInfo) getKernelInfo() {
+	var uname unix.Utsname
+	if err := unix.Uname(&uname); err != nil {
+		return
+	}
+
+	si.Kernel.Release = unix.ByteSliceToString(uname.Release[:])
+	si.Kernel.Version = unix.ByteSliceToString(uname.Version[:])
+	si.Kernel.Architecture = unix.ByteSliceToString(uname.Machine[:])
+}
@@ -2,26 +2,273 @@
 //
 // Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
 
-//+build linux
+//go:build linux
+// +build linux
 
 package sysinfo
 
 import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
 	"strings"
 	"syscall"
 	"unsafe"
 )
 
-// Kernel information.
-type Kernel struct {
-	Release      string `json:"release,omitempty"`
-	Version      string `json:"version,omitempty"`
-	Architecture string `json:"architecture,omitempty"`
+func (si *SysInfo) getProcessCounts() {
+	if procs, err := si.readDir("/proc"); err == nil {
+		si.Kernel.Processes = 0
+		for _, proc := range procs {
+			if _, err := strconv.ParseUint(proc.Name(), 10, 64); err == nil && proc.IsDir() {
+				si.Kernel.Processes++
+			}
+		}
+	}
+
+	// 0.20 0.18 0.12 1/80 11206, the 4th field is runnable/total scheduling entities (threads).
+	if fields := strings.Fields(si.slurpFile("/proc/loadavg")); len(fields) >= 4 {
+		if sl := strings.Split(fields[3], "/"); len(sl) == 2 {
+			if threads, err := strconv.ParseUint(sl[1], 10, 64); err == nil {
+				si.Kernel.Threads = uint(threads)
+			}
+		}
+	}
+}
+
+// Parse whitespace separated list of unsigned integers, like the /proc/sys/fs/file-nr.
+func parseUints(data string) []uint {
+	fields := strings.Fields(data)
+	values := make([]uint, len(fields))
+	for i, f := range fields {
+		v, _ := strconv.ParseUint(f, 10, 64)
+		values[i] = uint(v)
+	}
+
+	return values
+}
+
+func (si *SysInfo) getFileLimits() {
+	// allocated, free allocated (always 0 since 2.6), max
+	if fileNr := parseUints(si.slurpFile("/proc/sys/fs/file-nr")); len(fileNr) == 3 {
+		si.Kernel.Files = fileNr[0] - fileNr[1]
+		si.Kernel.FilesMax = fileNr[2]
+	}
+
+	// allocated, free allocated
+	if inodeNr := parseUints(si.slurpFile("/proc/sys/fs/inode-nr")); len(inodeNr) >= 2 {
+		si.Kernel.Inodes = inodeNr[0]
+		si.Kernel.InodesFree = inodeNr[1]
+	}
+}
+
+func (si *SysInfo) getEntropy() {
+	if avail, err := strconv.ParseUint(si.slurpFile("/proc/sys/kernel/random/entropy_avail"), 10, 64); err == nil {
+		si.Kernel.EntropyAvailable = uint(avail)
+	}
+	if size, err := strconv.ParseUint(si.slurpFile("/proc/sys/kernel/random/poolsize"), 10, 64); err == nil {
+		si.Kernel.EntropyPoolSize = uint(size)
+	}
+}
+
+// Timestamp at the end of the kernel version, like: Thu Oct  2 12:00:00 UTC 2025
+var reBuildDate = regexp.MustCompile(`(Mon|Tue|Wed|Thu|Fri|Sat|Sun) +[A-Z][a-z]{2} +\d+ \d+:\d+:\d+ (\S+ )?\d{4}$`)
+
+// Parse compiler & build date out of /proc/version:
+// Linux version 5.15.0-56-generic (buildd@lcy02-amd64-004) (gcc (Ubuntu 11.3.0-1ubuntu1~22.04) 11.3.0, GNU ld (GNU
+// Binutils for Ubuntu) 2.38) #62-Ubuntu SMP Tue Nov 22 19:54:14 UTC 2022
+func parseProcVersion(version string) (compiler, buildDate string) {
+	buildDate = reBuildDate.FindString(version)
+
+	// Compiler is the second parenthesized group, which has nested parentheses itself.
+	group, depth, start := 0, 0, 0
+	for i, c := range version {
+		switch c {
+		case '(':
+			if depth == 0 {
+				start = i + 1
+			}
+			depth++
+		case ')':
+			if depth == 0 {
+				continue
+			}
+			if depth--; depth == 0 {
+				if group++; group == 2 {
+					return version[start:i], buildDate
+				}
+			}
+		}
+	}
+
+	return "", buildDate
+}
+
+// Reasons for tainting the kernel, by bit, from Documentation/admin-guide/tainted-kernels.rst
+var taintReasons = [...]string{
+	"proprietary module was loaded",
+	"module was force loaded",
+	"kernel running on an out of specification system",
+	"module was force unloaded",
+	"processor reported a machine check exception",
+	"bad page referenced or some unexpected page flags",
+	"taint requested by userspace application",
+	"kernel died recently, i.e. there was an OOPS or BUG",
+	"ACPI table overridden by user",
+	"kernel issued warning",
+	"staging driver was loaded",
+	"workaround for bug in platform firmware applied",
+	"externally-built (out-of-tree) module was loaded",
+	"unsigned module was loaded",
+	"soft lockup occurred",
+	"kernel has been live patched",
+	"auxiliary taint, defined for and used by distros",
+	"kernel was built with the struct randomization plugin",
+	"an in-kernel test has been run",
+	"userspace used a mutating debug operation in fwctl",
+}
+
+func (si *SysInfo) getTaint() {
+	si.Kernel.Tainted, si.Kernel.TaintReasons = 0, nil
+
+	tainted, err := strconv.ParseUint(si.slurpFile("/proc/sys/kernel/tainted"), 10, 64)
+	if err != nil || tainted == 0 {
+		return
+	}
+
+	si.Kernel.Tainted = tainted
+	for bit, reason := range taintReasons {
+		if tainted&(1<<uint(bit)) != 0 {
+			si.Kernel.TaintReasons = append(si.Kernel.TaintReasons, reason)
+		}
+	}
+}
+
+// Tunables reported, unless configured otherwise.
+var defaultSysctls = []string{
+	"vm.swappiness",
+	"vm.dirty_ratio",
+	"vm.dirty_background_ratio",
+	"vm.overcommit_memory",
+	"net.core.somaxconn",
+}
+
+func (si *SysInfo) getSysctls() {
+	keys := si.Config.Sysctls
+	if keys == nil {
+		keys = defaultSysctls
+	}
+
+	si.Kernel.Sysctls = nil
+	for _, key := range keys {
+		value := si.slurpFile(path.Join("/proc/sys", strings.ReplaceAll(key, ".", "/")))
+		if value == "" {
+			continue
+		}
+		if si.Kernel.Sysctls == nil {
+			si.Kernel.Sysctls = make(map[string]string)
+		}
+		si.Kernel.Sysctls[key] = value
+	}
+}
+
+// Read the configuration the running kernel was built with, from /proc (needs CONFIG_IKCONFIG_PROC), or from /boot,
+// where distributions install it.
+func (si *SysInfo) readKernelConfig(release string) map[string]string {
+	var r io.Reader
+	if f, err := si.open("/proc/config.gz"); err == nil {
+		defer f.Close()
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			si.tracef("sysinfo: %v", err)
+			return nil
+		}
+		defer gz.Close()
+		r = gz
+	} else if f, err := si.open(path.Join("/boot", "config-"+release)); err == nil {
+		defer f.Close()
+		r = f
+	} else {
+		return nil
+	}
+
+	config := make(map[string]string)
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		if sl := strings.SplitN(s.Text(), "=", 2); len(sl) == 2 && strings.HasPrefix(sl[0], "CONFIG_") {
+			config[sl[0]] = sl[1]
+		}
+	}
+	if s.Err() != nil {
+		return nil
+	}
+
+	return config
+}
+
+// Preemption models, the way preempt= kernel parameter names them.
+var preemptModels = []struct {
+	config, model string
+}{
+	{"CONFIG_PREEMPT_RT", "rt"},
+	{"CONFIG_PREEMPT_LAZY", "lazy"},
+	{"CONFIG_PREEMPT", "full"},
+	{"CONFIG_PREEMPT_VOLUNTARY", "voluntary"},
+	{"CONFIG_PREEMPT_NONE", "none"},
+}
+
+func (si *SysInfo) getKernelConfig() {
+	si.Kernel.Preemption, si.Kernel.Tickless, si.Kernel.HZ = "", false, 0
+
+	config := si.readKernelConfig(si.Kernel.Release)
+	if config == nil {
+		return
+	}
+
+	for _, p := range preemptModels {
+		if config[p.config] == "y" {
+			si.Kernel.Preemption = p.model
+			break
+		}
+	}
+
+	// Model is only the default with dynamic preemption, it can be chosen at boot time, and changed at runtime.
+	if config["CONFIG_PREEMPT_DYNAMIC"] == "y" {
+		for _, param := range strings.Fields(si.slurpFile("/proc/cmdline")) {
+			if strings.HasPrefix(param, "preempt=") {
+				si.Kernel.Preemption = param[len("preempt="):]
+			}
+		}
+		// Active one is in parentheses, like: none voluntary (full) lazy
+		for _, model := range strings.Fields(si.slurpFile("/sys/kernel/debug/sched/preempt")) {
+			if strings.HasPrefix(model, "(") && strings.HasSuffix(model, ")") {
+				si.Kernel.Preemption = strings.Trim(model, "()")
+			}
+		}
+	}
+
+	si.Kernel.Tickless = config["CONFIG_NO_HZ_IDLE"] == "y" || config["CONFIG_NO_HZ_FULL"] == "y"
+	if hz, err := strconv.ParseUint(config["CONFIG_HZ"], 10, 64); err == nil {
+		si.Kernel.HZ = uint(hz)
+	}
 }
 
 func (si *SysInfo) getKernelInfo() {
-	si.Kernel.Release = slurpFile("/proc/sys/kernel/osrelease")
-	si.Kernel.Version = slurpFile("/proc/sys/kernel/version")
+	si.Kernel.Release = si.slurpFile("/proc/sys/kernel/osrelease")
+	si.Kernel.Version = si.slurpFile("/proc/sys/kernel/version")
+	si.getProcessCounts()
+	si.getFileLimits()
+	si.getSysctls()
+	si.getTaint()
+	si.getEntropy()
+	si.Kernel.Compiler, si.Kernel.BuildDate = parseProcVersion(si.slurpFile("/proc/version"))
+	si.getKernelConfig()
+	clocksource := "/sys/devices/system/clocksource/clocksource0"
+	si.Kernel.Clocksource = si.slurpFile(path.Join(clocksource, "current_clocksource"))
+	si.Kernel.AvailableClocksources = strings.Fields(si.slurpFile(path.Join(clocksource, "available_clocksource")))
 
 	var uname syscall.Utsname
 	if err := syscall.Uname(&uname); err != nil {
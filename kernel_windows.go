@@ -0,0 +1,19 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+func (si *SysInfo) getKernelInfo() {
+	// Unlike GetVersionEx, not subject to the application manifest compatibility shims.
+	v := windows.RtlGetVersion()
+
+	si.Kernel.Release = fmt.Sprintf("%d.%d.%d", v.MajorVersion, v.MinorVersion, v.BuildNumber)
+	si.Kernel.Version = windows.UTF16ToString(v.CsdVersion[:])
+}
@@ -5,17 +5,32 @@
 package sysinfo
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/binary"
-	"io/ioutil"
+	"path/filepath"
 	"strconv"
+	"strings"
 )
 
 // Memory information.
 type Memory struct {
-	Type  string `json:"type,omitempty"`
-	Speed uint   `json:"speed,omitempty"` // RAM data rate in MT/s
-	Size  uint   `json:"size,omitempty"`  // RAM size in MB
+	Type                 string        `json:"type,omitempty" msgpack:"type,omitempty"`
+	Speed                uint          `json:"speed,omitempty" msgpack:"mts,omitempty"`                // RAM data rate in MT/s
+	Size                 uint          `json:"size,omitempty" msgpack:"size,omitempty"`                // RAM size in MB
+	Installed            uint          `json:"installed,omitempty" msgpack:"inst,omitempty"`           // installed RAM modules size in MB
+	Usable               uint          `json:"usable,omitempty" msgpack:"usable,omitempty"`            // RAM size available to the kernel in MB
+	Errors               *MemoryErrors `json:"errors,omitempty" msgpack:"err,omitempty"`               // EDAC error counters, when available
+	TransparentHugePages string        `json:"transparenthugepages,omitempty" msgpack:"thp,omitempty"` // always, madvise or never
+	KSMEnabled           bool          `json:"ksmenabled,omitempty" msgpack:"ksm,omitempty"`           // kernel samepage merging
+	NUMANodes            []NUMANode    `json:"numanodes,omitempty" msgpack:"numa,omitempty"`           // on multi-node systems only
+	AutoNUMABalancing    bool          `json:"autonumabalancing,omitempty" msgpack:"numab,omitempty"`  // automatic NUMA balancing
+}
+
+// MemoryErrors counts memory errors detected by EDAC memory controllers.
+type MemoryErrors struct {
+	Corrected   uint64 `json:"corrected" msgpack:"ce"`
+	Uncorrected uint64 `json:"uncorrected" msgpack:"ue"`
 }
 
 func word(data []byte, index int) uint16 {
@@ -30,11 +45,87 @@ func qword(data []byte, index int) uint64 {
 	return binary.LittleEndian.Uint64(data[index : index+8])
 }
 
+// Sum error counts of all memory controllers. Without EDAC (VMs, consumer hardware) there's nothing to report.
+func (si *SysInfo) getMemoryErrors() *MemoryErrors {
+	controllers, err := si.glob("/sys/devices/system/edac/mc/mc[0-9]*")
+	if err != nil || len(controllers) == 0 {
+		return nil
+	}
+
+	errors := &MemoryErrors{}
+	for _, mc := range controllers {
+		ce, _ := strconv.ParseUint(si.slurpFile(filepath.Join(mc, "ce_count")), 10, 64)
+		ue, _ := strconv.ParseUint(si.slurpFile(filepath.Join(mc, "ue_count")), 10, 64)
+		errors.Corrected += ce
+		errors.Uncorrected += ue
+	}
+
+	return errors
+}
+
+// Active value of the sysfs choice attribute, the one in brackets, like: always [madvise] never
+func activeChoice(choices string) string {
+	for _, choice := range strings.Fields(choices) {
+		if strings.HasPrefix(choice, "[") && strings.HasSuffix(choice, "]") {
+			return strings.Trim(choice, "[]")
+		}
+	}
+
+	return ""
+}
+
+// Total usable RAM, as seen by the kernel, in MB.
+func (si *SysInfo) getUsableMemory() uint {
+	f, err := si.open("/proc/meminfo")
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	// MemTotal:       65747956 kB
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if fields := strings.Fields(s.Text()); len(fields) >= 2 && fields[0] == "MemTotal:" {
+			size, _ := strconv.ParseUint(fields[1], 10, 64)
+			return uint(size / 1024)
+		}
+	}
+
+	return 0
+}
+
+// Read raw SMBIOS table, or take it from the cache.
+func (si *SysInfo) readDMI() ([]byte, error) {
+	c := si.staticCache()
+	if c != nil && c.dmi != nil {
+		return c.dmi, nil
+	}
+
+	dmi, err := si.readFile("/sys/firmware/dmi/tables/DMI")
+	if err == nil && c != nil {
+		c.dmi = dmi
+	}
+
+	return dmi, err
+}
+
 func (si *SysInfo) getMemoryInfo() {
-	dmi, err := ioutil.ReadFile("/sys/firmware/dmi/tables/DMI")
+	// Installed size falls back to the usable size, unless DMI type 17 records tell better. The gap between the two is
+	// memory reserved by firmware & hardware.
+	si.Memory.Usable = si.getUsableMemory()
+	si.Memory.Installed = si.Memory.Usable
+	si.Memory.Errors = si.getMemoryErrors()
+	si.Memory.TransparentHugePages = activeChoice(si.slurpFile("/sys/kernel/mm/transparent_hugepage/enabled"))
+	si.Memory.KSMEnabled = si.slurpFile("/sys/kernel/mm/ksm/run") == "1"
+	// 1 balances the nodes, 2 promotes hot pages of memory tiers, 3 does both.
+	balancing := si.slurpFile("/proc/sys/kernel/numa_balancing")
+	si.Memory.AutoNUMABalancing = balancing != "" && balancing != "0"
+	si.getNUMANodes()
+
+	dmi, err := si.readDMI()
 	if err != nil {
 		// Xen hypervisor
-		if targetKB := slurpFile("/sys/devices/system/xen_memory/xen_memory0/target_kb"); targetKB != "" {
+		if targetKB := si.slurpFile("/sys/devices/system/xen_memory/xen_memory0/target_kb"); targetKB != "" {
 			si.Memory.Type = "DRAM"
 			size, _ := strconv.ParseUint(targetKB, 10, 64)
 			si.Memory.Size = uint(size) / 1024
@@ -113,6 +204,10 @@ loop:
 		}
 	}
 
+	if si.Memory.Size > 0 {
+		si.Memory.Installed = si.Memory.Size
+	}
+
 	// Sometimes DMI type 17 has no information, so we fall back to DMI type 19, to at least get the RAM size.
 	if si.Memory.Size == 0 && memSizeAlt > 0 {
 		si.Memory.Type = "DRAM"
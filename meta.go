@@ -8,11 +8,13 @@ import "time"
 
 // Meta information.
 type Meta struct {
-	Version   string    `json:"version"`
-	Timestamp time.Time `json:"timestamp"`
+	Version       string    `json:"version" msgpack:"ver"`
+	SchemaVersion int       `json:"schemaversion" msgpack:"sver"` // see SchemaVersion
+	Timestamp     time.Time `json:"timestamp" msgpack:"ts"`
 }
 
 func (si *SysInfo) getMetaInfo() {
 	si.Meta.Version = Version
+	si.Meta.SchemaVersion = SchemaVersion
 	si.Meta.Timestamp = time.Now()
 }
@@ -2,24 +2,41 @@
 //
 // Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
 
+//go:build !windows
+// +build !windows
+
 package sysinfo
 
 import (
-	"io/ioutil"
-	"os"
 	"path"
+	"strconv"
 	"strings"
 	"syscall"
 	"unsafe"
 )
 
-// NetworkDevice information.
-type NetworkDevice struct {
-	Name       string `json:"name,omitempty"`
-	Driver     string `json:"driver,omitempty"`
-	MACAddress string `json:"macaddress,omitempty"`
-	Port       string `json:"port,omitempty"`
-	Speed      uint   `json:"speed,omitempty"` // device max supported speed in Mbps
+func (si *SysInfo) getSRIOV(fullpath string) *SRIOVInfo {
+	devpath := path.Join(fullpath, "device")
+
+	// Virtual function links to its physical function, report PF's interface name, or its PCI address.
+	if physfn, err := si.readlink(path.Join(devpath, "physfn")); err == nil {
+		sriov := &SRIOVInfo{PhysicalFunction: path.Base(physfn)}
+		if ifaces, err := si.readDir(path.Join(devpath, "physfn", "net")); err == nil && len(ifaces) > 0 {
+			sriov.PhysicalFunction = ifaces[0].Name()
+		}
+		return sriov
+	}
+
+	totalVFs, err := strconv.ParseUint(si.slurpFile(path.Join(devpath, "sriov_totalvfs")), 10, 64)
+	if err != nil || totalVFs == 0 {
+		return nil
+	}
+	numVFs, _ := strconv.ParseUint(si.slurpFile(path.Join(devpath, "sriov_numvfs")), 10, 64)
+
+	return &SRIOVInfo{
+		NumVFs:   uint(numVFs),
+		TotalVFs: uint(totalVFs),
+	}
 }
 
 func getPortType(supp uint32) (port string) {
@@ -110,9 +127,131 @@ func getSupported(name string) uint32 {
 	return 0
 }
 
+func getDriverName(name string) string {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM, syscall.IPPROTO_IP)
+	if err != nil {
+		return ""
+	}
+	defer syscall.Close(fd)
+
+	// struct ethtool_drvinfo from /usr/include/linux/ethtool.h
+	var drvinfo struct {
+		Cmd         uint32
+		Driver      [32]byte
+		Version     [32]byte
+		FwVersion   [32]byte
+		BusInfo     [32]byte
+		EromVersion [32]byte
+		Reserved2   [12]byte
+		NPrivFlags  uint32
+		NStats      uint32
+		TestinfoLen uint32
+		EedumpLen   uint32
+		RegdumpLen  uint32
+	}
+
+	// ETHTOOL_GDRVINFO from /usr/include/linux/ethtool.h
+	const GDRVINFO = 0x3
+
+	drvinfo.Cmd = GDRVINFO
+
+	var ifr struct {
+		Name [16]byte
+		Data uintptr
+	}
+
+	copy(ifr.Name[:], name+"\000")
+	ifr.Data = uintptr(unsafe.Pointer(&drvinfo))
+
+	const SIOCETHTOOL = 0x8946
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), uintptr(SIOCETHTOOL), uintptr(unsafe.Pointer(&ifr)))
+	if errno != 0 {
+		return ""
+	}
+
+	return strings.TrimRight(string(drvinfo.Driver[:]), "\000")
+}
+
+// Find the PCI function backing the interface, walking up from its device, as some (like virtio) sit on a bus of
+// their own, below the PCI function. Virtual interfaces have no device at all.
+func (si *SysInfo) getPCIDevicePath(fullpath string) string {
+	devpath, err := si.evalSymlinks(path.Join(fullpath, "device"))
+	if err != nil {
+		return ""
+	}
+
+	for ; strings.HasPrefix(devpath, "/sys/devices/"); devpath = path.Dir(devpath) {
+		if subsystem, err := si.readlink(path.Join(devpath, "subsystem")); err == nil && path.Base(subsystem) == "pci" {
+			return devpath
+		}
+	}
+
+	return ""
+}
+
+// Hardware types from /usr/include/linux/if_arp.h
+var arphrdTypes = map[string]string{
+	"768": "ipip",
+	"769": "ip6tnl",
+	"776": "sit",
+	"778": "gre",
+	"823": "ip6gre",
+}
+
+// Classify virtual interface by its DEVTYPE, tun/tap flags, hardware type or, lastly, its driver.
+func (si *SysInfo) getInterfaceType(fullpath string) string {
+	for _, line := range strings.Split(si.slurpFile(path.Join(fullpath, "uevent")), "\n") {
+		if strings.HasPrefix(line, "DEVTYPE=") {
+			return strings.TrimPrefix(line, "DEVTYPE=")
+		}
+	}
+
+	hwType := si.slurpFile(path.Join(fullpath, "type"))
+
+	if _, err := si.stat(path.Join(fullpath, "tun_flags")); err == nil {
+		if hwType == "1" {
+			return "tap"
+		}
+		return "tun"
+	}
+
+	if t, ok := arphrdTypes[hwType]; ok {
+		return t
+	}
+
+	if !si.Config.Unprivileged {
+		switch driver := getDriverName(path.Base(fullpath)); driver {
+		case "veth", "wireguard", "vxlan", "geneve", "dummy":
+			return driver
+		}
+	}
+
+	return ""
+}
+
+// Resolve the peer of veth interface, through its iflink index. Peer in another network namespace can't be resolved
+// by name, so its index is reported instead.
+func (si *SysInfo) getVethPeer(sysClassNet, name string) string {
+	iflink := si.slurpFile(path.Join(sysClassNet, name, "iflink"))
+	if iflink == "" || iflink == si.slurpFile(path.Join(sysClassNet, name, "ifindex")) {
+		return ""
+	}
+
+	if devices, err := si.readDir(sysClassNet); err == nil {
+		for _, link := range devices {
+			if si.slurpFile(path.Join(sysClassNet, link.Name(), "ifindex")) == iflink {
+				return link.Name()
+			}
+		}
+	}
+
+	return iflink
+}
+
 func (si *SysInfo) getNetworkInfo() {
 	sysClassNet := "/sys/class/net"
-	devices, err := ioutil.ReadDir(sysClassNet)
+	devices, err := si.readDir(sysClassNet)
 	if err != nil {
 		return
 	}
@@ -120,28 +259,56 @@ func (si *SysInfo) getNetworkInfo() {
 	si.Network = make([]NetworkDevice, 0)
 	for _, link := range devices {
 		fullpath := path.Join(sysClassNet, link.Name())
-		dev, err := os.Readlink(fullpath)
+		dev, err := si.readlink(fullpath)
 		if err != nil {
 			continue
 		}
 
+		// Virtual interfaces are reported only when their type is recognized, skipping loopback and the like.
+		var ifType string
 		if strings.HasPrefix(dev, "../../devices/virtual/") {
-			continue
+			if ifType = si.getInterfaceType(fullpath); ifType == "" {
+				continue
+			}
 		}
 
-		supp := getSupported(link.Name())
-
 		device := NetworkDevice{
 			Name:       link.Name(),
-			MACAddress: slurpFile(path.Join(fullpath, "address")),
-			Port:       getPortType(supp),
-			Speed:      getMaxSpeed(supp),
+			MACAddress: si.slurpFile(path.Join(fullpath, "address")),
+			NUMANode:   si.getNUMANode(path.Join(fullpath, "device")),
+			SRIOV:      si.getSRIOV(fullpath),
+			Wireless:   si.getWireless(fullpath),
+			Type:       ifType,
+		}
+
+		// Ioctls failing for lack of privileges (EPERM) just leave their fields empty.
+		if !si.Config.Unprivileged {
+			supp := getSupported(link.Name())
+			device.Port = getPortType(supp)
+			device.Speed = getMaxSpeed(supp)
+			device.Offloads = getOffloads(link.Name())
+		}
+
+		// IFF_PROMISC from /usr/include/linux/if.h
+		if flags, err := strconv.ParseUint(strings.TrimPrefix(si.slurpFile(path.Join(fullpath, "flags")), "0x"), 16, 64); err == nil {
+			device.Promiscuous = flags&0x100 != 0
 		}
 
-		if driver, err := os.Readlink(path.Join(fullpath, "device", "driver")); err == nil {
+		if ifType == "veth" {
+			device.Peer = si.getVethPeer(sysClassNet, link.Name())
+		}
+
+		if driver, err := si.readlink(path.Join(fullpath, "device", "driver")); err == nil {
 			device.Driver = path.Base(driver)
 		}
 
+		if pcipath := si.getPCIDevicePath(fullpath); pcipath != "" {
+			device.PCIAddress = path.Base(pcipath)
+			device.PCIVendor = si.slurpFile(path.Join(pcipath, "vendor"))
+			device.PCIDevice = si.slurpFile(path.Join(pcipath, "device"))
+			device.Vendor, device.Model = si.lookupPCI(device.PCIVendor, device.PCIDevice)
+		}
+
 		si.Network = append(si.Network, device)
 	}
 }
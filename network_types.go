@@ -0,0 +1,41 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+// NetworkDevice information.
+type NetworkDevice struct {
+	Name        string          `json:"name,omitempty" msgpack:"name,omitempty"`
+	Driver      string          `json:"driver,omitempty" msgpack:"drv,omitempty"`
+	MACAddress  string          `json:"macaddress,omitempty" msgpack:"mac,omitempty"`
+	Port        string          `json:"port,omitempty" msgpack:"port,omitempty"`
+	Speed       uint            `json:"speed,omitempty" msgpack:"speed,omitempty"`   // device max supported speed in Mbps
+	NUMANode    *int            `json:"numanode,omitempty" msgpack:"numa,omitempty"` // NUMA node the device is attached to
+	SRIOV       *SRIOVInfo      `json:"sriov,omitempty" msgpack:"sriov,omitempty"`
+	Wireless    *WirelessInfo   `json:"wireless,omitempty" msgpack:"wifi,omitempty"`
+	Type        string          `json:"type,omitempty" msgpack:"type,omitempty"`      // virtual interface type, like vxlan, veth or tun
+	Peer        string          `json:"peer,omitempty" msgpack:"peer,omitempty"`      // the other end of veth pair, name or ifindex
+	Vendor      string          `json:"vendor,omitempty" msgpack:"vnd,omitempty"`     // PCI vendor name
+	Model       string          `json:"model,omitempty" msgpack:"model,omitempty"`    // PCI device name
+	PCIAddress  string          `json:"pciaddress,omitempty" msgpack:"pci,omitempty"` // like 0000:03:00.1
+	PCIVendor   string          `json:"pcivendor,omitempty" msgpack:"pciv,omitempty"` // PCI vendor ID, like 0x8086
+	PCIDevice   string          `json:"pcidevice,omitempty" msgpack:"pcid,omitempty"` // PCI device ID
+	Promiscuous bool            `json:"promiscuous,omitempty" msgpack:"promisc,omitempty"`
+	Offloads    map[string]bool `json:"offloads,omitempty" msgpack:"offl,omitempty"` // offload features (like rx-gro) and whether they are active
+}
+
+// SRIOVInfo describes SR-IOV physical function (PF) or virtual function (VF).
+type SRIOVInfo struct {
+	NumVFs           uint   `json:"numvfs,omitempty" msgpack:"vfs,omitempty"`    // enabled VFs, on PF
+	TotalVFs         uint   `json:"totalvfs,omitempty" msgpack:"tvfs,omitempty"` // supported VFs, on PF
+	PhysicalFunction string `json:"physfn,omitempty" msgpack:"pf,omitempty"`     // parent PF interface, on VF
+}
+
+// WirelessInfo describes a wireless network interface.
+type WirelessInfo struct {
+	SSID      string `json:"ssid,omitempty" msgpack:"ssid,omitempty"`
+	Signal    int    `json:"signal,omitempty" msgpack:"sig,omitempty"`     // signal level in dBm
+	Frequency uint   `json:"frequency,omitempty" msgpack:"freq,omitempty"` // in MHz
+	Channel   uint   `json:"channel,omitempty" msgpack:"chan,omitempty"`
+}
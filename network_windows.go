@@ -0,0 +1,9 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+// Network devices are not collected on Windows, yet.
+func (si *SysInfo) getNetworkInfo() {
+}
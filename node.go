@@ -6,94 +6,97 @@ package sysinfo
 
 import (
 	"bufio"
-	"crypto/rand"
-	"fmt"
+	"context"
+	"net"
 	"os"
 	"strings"
 	"time"
 )
 
+// Upper bound on the FQDN lookup, so slow or unreachable DNS doesn't hold up the rest.
+const fqdnTimeout = time.Second
+
 // Node information.
 type Node struct {
-	Hostname   string `json:"hostname,omitempty"`
-	MachineID  string `json:"machineid,omitempty"`
-	Hypervisor string `json:"hypervisor,omitempty"`
-	Timezone   string `json:"timezone,omitempty"`
+	Hostname   string   `json:"hostname,omitempty" msgpack:"host,omitempty"`
+	FQDN       string   `json:"fqdn,omitempty" msgpack:"fqdn,omitempty"` // fully qualified hostname, as resolved
+	MachineID  string   `json:"machineid,omitempty" msgpack:"mid,omitempty"`
+	Hypervisor string   `json:"hypervisor,omitempty" msgpack:"hv,omitempty"`
+	VirtRole   string   `json:"virtrole,omitempty" msgpack:"vr,omitempty"` // host, guest or none
+	Timezone   string   `json:"timezone,omitempty" msgpack:"tz,omitempty"`
+	Users      []string `json:"users,omitempty" msgpack:"usr,omitempty"`    // logged in users
+	Sessions   uint     `json:"sessions,omitempty" msgpack:"ses,omitempty"` // number of user sessions
 }
 
 func (si *SysInfo) getHostname() {
-	si.Node.Hostname = slurpFile("/proc/sys/kernel/hostname")
+	si.Node.Hostname = si.slurpFile("/proc/sys/kernel/hostname")
 }
 
-func (si *SysInfo) getSetMachineID() {
-	const pathSystemdMachineID = "/etc/machine-id"
-	const pathDbusMachineID = "/var/lib/dbus/machine-id"
-
-	systemdMachineID := slurpFile(pathSystemdMachineID)
-	dbusMachineID := slurpFile(pathDbusMachineID)
-
-	if systemdMachineID != "" && dbusMachineID != "" {
-		// All OK, just return the machine id.
-		if systemdMachineID == dbusMachineID {
-			si.Node.MachineID = systemdMachineID
-			return
-		}
-
-		// They both exist, but they don't match! Copy systemd machine id to DBUS machine id.
-		spewFile(pathDbusMachineID, systemdMachineID, 0444)
-		si.Node.MachineID = systemdMachineID
+// Resolve the hostname to its addresses, then back to the name, the way hostname -f does it, /etc/hosts included.
+// Names resolved are taken only when they extend the hostname, and the whole lookup is bounded by a short timeout.
+func (si *SysInfo) getFQDN() {
+	if strings.Contains(si.Node.Hostname, ".") {
+		si.Node.FQDN = si.Node.Hostname
 		return
 	}
-
-	// Copy DBUS machine id to non-existent systemd machine id.
-	if systemdMachineID == "" && dbusMachineID != "" {
-		spewFile(pathSystemdMachineID, dbusMachineID, 0444)
-		si.Node.MachineID = dbusMachineID
+	if si.Node.Hostname == "" {
 		return
 	}
 
-	// Copy systemd machine id to non-existent DBUS machine id.
-	if systemdMachineID != "" && dbusMachineID == "" {
-		spewFile(pathDbusMachineID, systemdMachineID, 0444)
-		si.Node.MachineID = systemdMachineID
+	ctx, cancel := context.WithTimeout(context.Background(), fqdnTimeout)
+	defer cancel()
+
+	addrs, err := net.DefaultResolver.LookupHost(ctx, si.Node.Hostname)
+	if err != nil {
 		return
 	}
 
-	// Generate and write fresh new machine ID to both locations, conforming to the DBUS specification:
-	// https://dbus.freedesktop.org/doc/dbus-specification.html#uuids
-
-	random := make([]byte, 12)
-	if _, err := rand.Read(random); err != nil {
-		return
+	for _, addr := range addrs {
+		names, err := net.DefaultResolver.LookupAddr(ctx, addr)
+		if err != nil {
+			continue
+		}
+		for _, name := range names {
+			if name = strings.TrimSuffix(name, "."); strings.HasPrefix(name, si.Node.Hostname+".") {
+				si.Node.FQDN = name
+				return
+			}
+		}
 	}
-	newMachineID := fmt.Sprintf("%x%x", random, time.Now().Unix())
+}
 
-	spewFile(pathSystemdMachineID, newMachineID, 0444)
-	spewFile(pathDbusMachineID, newMachineID, 0444)
-	si.Node.MachineID = newMachineID
+// Machine ID is read from where systemd keeps it, falling back to where DBUS does. It's never synced nor generated,
+// system identity is none of our business.
+func (si *SysInfo) getMachineID() {
+	for _, p := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+		if machineID := si.slurpFile(p); machineID != "" {
+			si.Node.MachineID = machineID
+			return
+		}
+	}
 }
 
 func (si *SysInfo) getTimezone() {
 	const zoneInfoPrefix = "/usr/share/zoneinfo/"
 
-	if fi, err := os.Lstat("/etc/localtime"); err == nil {
-		if fi.Mode()&os.ModeSymlink == os.ModeSymlink {
-			if tzfile, err := os.Readlink("/etc/localtime"); err == nil {
-				tzfile = strings.TrimPrefix(tzfile, "..")
-				if strings.HasPrefix(tzfile, zoneInfoPrefix) {
-					si.Node.Timezone = strings.TrimPrefix(tzfile, zoneInfoPrefix)
-					return
-				}
-			}
+	// Fails on anything but a symlink.
+	if tzfile, err := si.readlink("/etc/localtime"); err == nil {
+		tzfile = strings.TrimPrefix(tzfile, "..")
+		if strings.HasPrefix(tzfile, zoneInfoPrefix) {
+			// Variants of the database, with and without leap seconds, carry the same IANA names.
+			tzfile = strings.TrimPrefix(tzfile, zoneInfoPrefix)
+			tzfile = strings.TrimPrefix(strings.TrimPrefix(tzfile, "posix/"), "right/")
+			si.Node.Timezone = tzfile
+			return
 		}
 	}
 
-	if timezone := slurpFile("/etc/timezone"); timezone != "" {
+	if timezone := si.slurpFile("/etc/timezone"); timezone != "" {
 		si.Node.Timezone = timezone
 		return
 	}
 
-	if f, err := os.Open("/etc/sysconfig/clock"); err == nil {
+	if f, err := si.open("/etc/sysconfig/clock"); err == nil {
 		defer f.Close()
 		s := bufio.NewScanner(f)
 		for s.Scan() {
@@ -105,11 +108,29 @@ func (si *SysInfo) getTimezone() {
 			}
 		}
 	}
+
+	// Last resort, when none of the files exist (like in minimal containers). TZ can name a zone file, optionally
+	// prefixed with colon, absolute or relative to the database, or hold a POSIX TZ rule, which is reported as is.
+	// Environment is not of the system the copy of the files is from, either.
+	if si.Config.FS != nil {
+		return
+	}
+	for _, name := range []string{"/etc/localtime", "/etc/timezone", "/etc/sysconfig/clock"} {
+		if _, err := si.stat(name); err == nil {
+			return
+		}
+	}
+	if tz := strings.TrimPrefix(os.Getenv("TZ"), ":"); tz != "" {
+		si.Node.Timezone = strings.TrimPrefix(tz, zoneInfoPrefix)
+	}
 }
 
 func (si *SysInfo) getNodeInfo() {
 	si.getHostname()
-	si.getSetMachineID()
+	si.getFQDN()
+	si.getMachineID()
 	si.getHypervisor()
+	si.getVirtRole() // depends on Product info
 	si.getTimezone()
+	si.getUsers()
 }
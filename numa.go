@@ -0,0 +1,74 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+import (
+	"bufio"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NUMANode information.
+type NUMANode struct {
+	ID       uint   `json:"id" msgpack:"id"`
+	CPUs     string `json:"cpus,omitempty" msgpack:"cpus,omitempty"`     // CPU list, like 0-15,32-47
+	MemTotal uint   `json:"memtotal,omitempty" msgpack:"mtot,omitempty"` // in MB
+	MemFree  uint   `json:"memfree,omitempty" msgpack:"mfree,omitempty"` // in MB
+	MemUsed  uint   `json:"memused,omitempty" msgpack:"mused,omitempty"` // in MB
+}
+
+// Parse per node meminfo, in MB, lines look like: Node 0 MemTotal:       16318284 kB
+func (si *SysInfo) readNodeMeminfo(nodepath string) map[string]uint {
+	f, err := si.open(filepath.Join(nodepath, "meminfo"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	meminfo := make(map[string]uint)
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		if size, err := strconv.ParseUint(fields[3], 10, 64); err == nil {
+			meminfo[strings.TrimSuffix(fields[2], ":")] = uint(size / 1024)
+		}
+	}
+
+	return meminfo
+}
+
+// Enumerate NUMA nodes, only on systems having more than one, as there's no imbalance to spot otherwise.
+func (si *SysInfo) getNUMANodes() {
+	si.Memory.NUMANodes = nil
+
+	nodes, err := si.glob("/sys/devices/system/node/node[0-9]*")
+	if err != nil || len(nodes) < 2 {
+		return
+	}
+
+	for _, nodepath := range nodes {
+		id, err := strconv.ParseUint(strings.TrimPrefix(filepath.Base(nodepath), "node"), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		meminfo := si.readNodeMeminfo(nodepath)
+		si.Memory.NUMANodes = append(si.Memory.NUMANodes, NUMANode{
+			ID:       uint(id),
+			CPUs:     si.slurpFile(filepath.Join(nodepath, "cpulist")),
+			MemTotal: meminfo["MemTotal"],
+			MemFree:  meminfo["MemFree"],
+			MemUsed:  meminfo["MemUsed"],
+		})
+	}
+
+	// Glob sorts node10 before node2.
+	sort.Slice(si.Memory.NUMANodes, func(i, j int) bool { return si.Memory.NUMANodes[i].ID < si.Memory.NUMANodes[j].ID })
+}
@@ -0,0 +1,173 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package sysinfo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// struct nvme_admin_cmd from /usr/include/linux/nvme_ioctl.h
+type nvmeAdminCmd struct {
+	Opcode      uint8
+	Flags       uint8
+	Rsvd1       uint16
+	NSID        uint32
+	Cdw2        uint32
+	Cdw3        uint32
+	Metadata    uint64
+	Addr        uint64
+	MetadataLen uint32
+	DataLen     uint32
+	Cdw10       uint32
+	Cdw11       uint32
+	Cdw12       uint32
+	Cdw13       uint32
+	Cdw14       uint32
+	Cdw15       uint32
+	TimeoutMs   uint32
+	Result      uint32
+}
+
+const (
+	nvmeIoctlAdminCmd = 0xc0484e41 // _IOWR('N', 0x41, struct nvme_admin_cmd)
+	nvmeAdminGetLog   = 0x02
+	nvmeAdminIdentify = 0x06
+	nvmeAdminSecRecv  = 0x82
+	nvmeIdentifyCtrl  = 0x01 // CNS value for Identify Controller data structure
+	nvmeLogSMART      = 0x02
+	nvmeTimeout       = 5000 // ms
+)
+
+// NVMe SMART / Health Information log page, the parts we care about.
+type nvmeSMARTLog struct {
+	CriticalWarning byte
+	PercentageUsed  uint
+	PowerOnHours    uint64
+}
+
+// Critical warning bits of the SMART / Health Information log page.
+var nvmeCriticalWarnings = []string{
+	"spare_below_threshold",
+	"temperature",
+	"reliability_degraded",
+	"read_only",
+	"volatile_backup_failed",
+	"pmr_read_only",
+}
+
+// Names of the critical warnings flagged, reserved bits included, as they're numbered.
+func (log *nvmeSMARTLog) criticalWarnings() []string {
+	var warnings []string
+	for bit := uint(0); bit < 8; bit++ {
+		if log.CriticalWarning&(1<<bit) == 0 {
+			continue
+		}
+		if bit < uint(len(nvmeCriticalWarnings)) {
+			warnings = append(warnings, nvmeCriticalWarnings[bit])
+		} else {
+			warnings = append(warnings, fmt.Sprintf("bit%d", bit))
+		}
+	}
+
+	return warnings
+}
+
+// Issue the admin command through the NVMe passthrough, reading the data into the buffer.
+func nvmeAdminCommand(devpath string, cmd nvmeAdminCmd, data []byte) error {
+	fd, err := unix.Open(devpath, unix.O_RDONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	cmd.Addr = uint64(uintptr(unsafe.Pointer(&data[0])))
+	cmd.DataLen = uint32(len(data))
+	cmd.TimeoutMs = nvmeTimeout
+
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), nvmeIoctlAdminCmd, uintptr(unsafe.Pointer(&cmd)))
+	runtime.KeepAlive(data)
+	if errno != 0 {
+		return errno
+	}
+
+	return nil
+}
+
+// Read SMART / Health Information log page through the NVMe admin command passthrough.
+func getNVMeSMARTLog(devpath string) (*nvmeSMARTLog, error) {
+	data := make([]byte, 512)
+	cmd := nvmeAdminCmd{
+		Opcode: nvmeAdminGetLog,
+		NSID:   0xffffffff,                               // controller wide
+		Cdw10:  uint32(len(data)/4-1)<<16 | nvmeLogSMART, // number of dwords (0's based) & log page ID
+	}
+	if err := nvmeAdminCommand(devpath, cmd, data); err != nil {
+		return nil, err
+	}
+
+	// NVM Express Base Specification, Get Log Page - SMART / Health Information (Log Identifier 02h)
+	return &nvmeSMARTLog{
+		CriticalWarning: data[0],
+		PercentageUsed:  uint(data[5]),
+		PowerOnHours:    binary.LittleEndian.Uint64(data[128:136]),
+	}, nil
+}
+
+// Self-encrypting drives are managed by TCG security protocols (Opal), carried by Security Send & Receive commands.
+// Plenty of drives support the commands for other reasons, so the list of security protocols supported must have
+// the TCG one.
+func isNVMeSelfEncrypting(devpath string) bool {
+	data := make([]byte, 4096)
+	cmd := nvmeAdminCmd{
+		Opcode: nvmeAdminIdentify,
+		Cdw10:  nvmeIdentifyCtrl,
+	}
+	if err := nvmeAdminCommand(devpath, cmd, data); err != nil {
+		return false
+	}
+
+	// Optional Admin Command Support (OACS), bit 0: Security Send and Security Receive supported
+	if binary.LittleEndian.Uint16(data[256:258])&0x1 == 0 {
+		return false
+	}
+
+	// Security protocol 00h, SP specific 0000h: supported security protocol list, from SPC-5.
+	data = make([]byte, 512)
+	cmd = nvmeAdminCmd{
+		Opcode: nvmeAdminSecRecv,
+		Cdw10:  0x00<<24 | 0x0000<<8,
+		Cdw11:  uint32(len(data)), // allocation length
+	}
+	if err := nvmeAdminCommand(devpath, cmd, data); err != nil {
+		return false
+	}
+
+	return hasTCGProtocol(data)
+}
+
+// List of the security protocols supported, big endian length at byte 6, protocol IDs following at byte 8. TCG
+// protocol is 01h.
+func hasTCGProtocol(data []byte) bool {
+	if len(data) < 8 {
+		return false
+	}
+
+	n := int(binary.BigEndian.Uint16(data[6:8]))
+	for i := 8; i < 8+n && i < len(data); i++ {
+		if data[i] == 0x01 {
+			return true
+		}
+	}
+
+	return false
+}
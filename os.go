@@ -6,18 +6,26 @@ package sysinfo
 
 import (
 	"bufio"
-	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 )
 
 // OS information.
 type OS struct {
-	Name         string `json:"name,omitempty"`
-	Vendor       string `json:"vendor,omitempty"`
-	Version      string `json:"version,omitempty"`
-	Release      string `json:"release,omitempty"`
-	Architecture string `json:"architecture,omitempty"`
+	Name           string   `json:"name,omitempty" msgpack:"name,omitempty"`
+	Vendor         string   `json:"vendor,omitempty" msgpack:"vnd,omitempty"`
+	Version        string   `json:"version,omitempty" msgpack:"ver,omitempty"`
+	Release        string   `json:"release,omitempty" msgpack:"rel,omitempty"`
+	Architecture   string   `json:"architecture,omitempty" msgpack:"arch,omitempty"`
+	SecurityModule string   `json:"securitymodule,omitempty" msgpack:"lsm,omitempty"` // active LSM, selinux or apparmor
+	SecurityMode   string   `json:"securitymode,omitempty" msgpack:"lsmm,omitempty"`  // enforcing, permissive, complain...
+	DNSServers     []string `json:"dnsservers,omitempty" msgpack:"dns,omitempty"`
+	RootDevice     string   `json:"rootdevice,omitempty" msgpack:"rdev,omitempty"` // disk the root filesystem lives on
+	RootPartition  string   `json:"rootpartition,omitempty" msgpack:"rpart,omitempty"`
+	BootDevice     string   `json:"bootdevice,omitempty" msgpack:"bdev,omitempty"` // disk the system booted from
+	WSL            string   `json:"wsl,omitempty" msgpack:"wsl,omitempty"`         // wsl1 or wsl2, when running under Windows Subsystem for Linux
+	Firewall       string   `json:"firewall,omitempty" msgpack:"fw,omitempty"`     // nftables, iptables-nft or iptables-legacy
 }
 
 var (
@@ -29,15 +37,110 @@ var (
 	reRedHat     = regexp.MustCompile(`[\( ]([\d\.]+)`)
 )
 
+func (si *SysInfo) getSecurityModule() {
+	switch si.slurpFile("/sys/fs/selinux/enforce") {
+	case "1":
+		si.OS.SecurityModule, si.OS.SecurityMode = "selinux", "enforcing"
+		return
+	case "0":
+		si.OS.SecurityModule, si.OS.SecurityMode = "selinux", "permissive"
+		return
+	}
+
+	if _, err := si.stat("/sys/kernel/security/apparmor/profiles"); err == nil {
+		si.OS.SecurityModule = "apparmor"
+		if si.OS.SecurityMode = si.slurpFile("/sys/module/apparmor/parameters/mode"); si.OS.SecurityMode == "" {
+			si.OS.SecurityMode = "enabled"
+		}
+	}
+}
+
+// Parse nameserver lines of resolv.conf(5).
+func (si *SysInfo) readNameservers(resolvConf string) []string {
+	f, err := si.open(resolvConf)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var servers []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if fields := strings.Fields(s.Text()); len(fields) >= 2 && fields[0] == "nameserver" {
+			servers = append(servers, fields[1])
+		}
+	}
+
+	return servers
+}
+
+func (si *SysInfo) getDNSServers() {
+	si.OS.DNSServers = si.readNameservers("/etc/resolv.conf")
+
+	// With systemd-resolved stub resolver, the real upstream servers are listed elsewhere.
+	if len(si.OS.DNSServers) == 1 && si.OS.DNSServers[0] == "127.0.0.53" {
+		if servers := si.readNameservers("/run/systemd/resolve/resolv.conf"); len(servers) > 0 {
+			si.OS.DNSServers = servers
+		}
+	}
+}
+
+// WSL1 translates syscalls, reporting kernel release like 4.4.0-19041-Microsoft, WSL2 runs a real kernel, like
+// 5.15.90.1-microsoft-standard-WSL2. Custom WSL2 kernels can be told only by the WSL runtime directory.
+func (si *SysInfo) getWSL() string {
+	version := strings.ToLower(si.slurpFile("/proc/version"))
+	if strings.Contains(version, "microsoft") || strings.Contains(version, "wsl") {
+		if strings.Contains(version, "wsl2") || strings.Contains(version, "microsoft-standard") {
+			return "wsl2"
+		}
+		return "wsl1"
+	}
+
+	if _, err := si.stat("/run/WSL"); err == nil {
+		return "wsl2"
+	}
+
+	return ""
+}
+
+// Tell the firewall backend by where the iptables command leads, xtables-nft-multi or xtables-legacy-multi, through
+// the alternatives symlinks. Without iptables installed, loaded kernel modules tell which backend is in use.
+func (si *SysInfo) getFirewall() string {
+	for _, iptables := range []string{"/usr/sbin/iptables", "/sbin/iptables"} {
+		if target, err := si.evalSymlinks(iptables); err == nil {
+			switch base := filepath.Base(target); {
+			case strings.Contains(base, "nft"):
+				return "iptables-nft"
+			case strings.Contains(base, "legacy"), base == "iptables", base == "xtables-multi", base == "iptables-multi":
+				return "iptables-legacy"
+			}
+		}
+	}
+
+	if _, err := si.stat("/sys/module/nf_tables"); err == nil {
+		return "nftables"
+	}
+	if _, err := si.stat("/proc/net/ip_tables_names"); err == nil {
+		return "iptables-legacy"
+	}
+
+	return ""
+}
+
 func (si *SysInfo) getOSInfo() {
 	// This seems to be the best and most portable way to detect OS architecture (NOT kernel!)
-	if _, err := os.Stat("/lib64/ld-linux-x86-64.so.2"); err == nil {
+	if _, err := si.stat("/lib64/ld-linux-x86-64.so.2"); err == nil {
 		si.OS.Architecture = "amd64"
-	} else if _, err := os.Stat("/lib/ld-linux.so.2"); err == nil {
+	} else if _, err := si.stat("/lib/ld-linux.so.2"); err == nil {
 		si.OS.Architecture = "i386"
 	}
 
-	f, err := os.Open("/etc/os-release")
+	si.getSecurityModule()
+	si.getDNSServers()
+	si.OS.WSL = si.getWSL()
+	si.OS.Firewall = si.getFirewall()
+
+	f, err := si.open("/etc/os-release")
 	if err != nil {
 		return
 	}
@@ -56,19 +159,19 @@ func (si *SysInfo) getOSInfo() {
 
 	switch si.OS.Vendor {
 	case "debian":
-		si.OS.Release = slurpFile("/etc/debian_version")
+		si.OS.Release = si.slurpFile("/etc/debian_version")
 	case "ubuntu":
 		if m := reUbuntu.FindStringSubmatch(si.OS.Name); m != nil {
 			si.OS.Release = m[1]
 		}
 	case "centos":
-		if release := slurpFile("/etc/centos-release"); release != "" {
+		if release := si.slurpFile("/etc/centos-release"); release != "" {
 			if m := reCentOS.FindStringSubmatch(release); m != nil {
 				si.OS.Release = m[2]
 			}
 		}
 	case "rhel":
-		if release := slurpFile("/etc/redhat-release"); release != "" {
+		if release := si.slurpFile("/etc/redhat-release"); release != "" {
 			if m := reRedHat.FindStringSubmatch(release); m != nil {
 				si.OS.Release = m[1]
 			}
@@ -0,0 +1,112 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Copy of pci.ids compiled into the binary, unless built with the nopciids build tag.
+var embeddedPCIIDs []byte
+
+// Locations of the system PCI ID database, on various distributions.
+var pciIDsPaths = []string{
+	"/usr/share/hwdata/pci.ids",
+	"/usr/share/misc/pci.ids",
+	"/usr/share/pci.ids",
+}
+
+var (
+	embeddedPCINamesOnce sync.Once
+	embeddedPCINames     map[string]string
+	systemPCINamesOnce   sync.Once
+	systemPCINames       map[string]string
+)
+
+// Parse pci.ids into names keyed by vendor ID, and vendor:device ID pairs. Subsystems and device classes that
+// follow are not needed.
+func parsePCIIDs(r io.Reader) map[string]string {
+	names := make(map[string]string)
+
+	var vendor string
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := s.Text()
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if strings.HasPrefix(line, "C ") {
+			break
+		}
+		if strings.HasPrefix(line, "\t\t") {
+			continue
+		}
+
+		// 10de  NVIDIA Corporation
+		// 	2204  GA102 [GeForce RTX 3090]
+		fields := strings.SplitN(strings.TrimPrefix(line, "\t"), "  ", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		if line[0] == '\t' {
+			if vendor != "" {
+				names[vendor+":"+fields[0]] = fields[1]
+			}
+			continue
+		}
+		vendor = fields[0]
+		names[vendor] = fields[1]
+	}
+
+	return names
+}
+
+func (si *SysInfo) readPCINames() map[string]string {
+	for _, p := range pciIDsPaths {
+		if f, err := si.open(p); err == nil {
+			defer f.Close()
+			return parsePCIIDs(f)
+		}
+	}
+
+	return nil
+}
+
+// Resolve PCI vendor & device IDs (like 0x10de, 0x2204) to names. Embedded copy is consulted first, system database
+// fills in what it doesn't know, as embedded copy can be older. System database is parsed only once, unless it's read
+// from Config.FS, which can be a different system every time.
+func (si *SysInfo) lookupPCI(vendorID, deviceID string) (vendor, device string) {
+	embeddedPCINamesOnce.Do(func() {
+		if len(embeddedPCIIDs) > 0 {
+			embeddedPCINames = parsePCIIDs(bytes.NewReader(embeddedPCIIDs))
+		}
+	})
+
+	var systemNames map[string]string
+	if si.Config.FS == nil {
+		systemPCINamesOnce.Do(func() { systemPCINames = si.readPCINames() })
+		systemNames = systemPCINames
+	} else {
+		systemNames = si.readPCINames()
+	}
+
+	vendorID = strings.ToLower(strings.TrimPrefix(vendorID, "0x"))
+	deviceID = strings.ToLower(strings.TrimPrefix(deviceID, "0x"))
+
+	for _, names := range []map[string]string{embeddedPCINames, systemNames} {
+		if vendor == "" {
+			vendor = names[vendorID]
+		}
+		if device == "" {
+			device = names[vendorID+":"+deviceID]
+		}
+	}
+
+	return
+}
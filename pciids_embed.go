@@ -0,0 +1,19 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+//go:build !nopciids
+// +build !nopciids
+
+package sysinfo
+
+import _ "embed" // for go:embed
+
+//go:generate go run gen_pciids.go
+
+//go:embed pci.ids
+var pciIDs []byte
+
+func init() {
+	embeddedPCIIDs = pciIDs
+}
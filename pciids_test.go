@@ -0,0 +1,20 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+//go:build !nopciids
+// +build !nopciids
+
+package sysinfo
+
+import "testing"
+
+func TestLookupPCI(t *testing.T) {
+	// No system database, only the embedded copy.
+	si := SysInfo{Config: Config{FS: newTestFS(nil, nil)}}
+
+	vendor, device := si.lookupPCI("0x10de", "0x2204")
+	if vendor != "NVIDIA Corporation" || device != "GA102 [GeForce RTX 3090]" {
+		t.Errorf("got %q %q, want NVIDIA Corporation GA102 [GeForce RTX 3090]", vendor, device)
+	}
+}
@@ -6,15 +6,17 @@ package sysinfo
 
 // Product information.
 type Product struct {
-	Name    string `json:"name,omitempty"`
-	Vendor  string `json:"vendor,omitempty"`
-	Version string `json:"version,omitempty"`
-	Serial  string `json:"serial,omitempty"`
+	Name    string `json:"name,omitempty" msgpack:"name,omitempty"`
+	Vendor  string `json:"vendor,omitempty" msgpack:"vnd,omitempty"`
+	Version string `json:"version,omitempty" msgpack:"ver,omitempty"`
+	Serial  string `json:"serial,omitempty" msgpack:"sn,omitempty"`
+	UUID    string `json:"uuid,omitempty" msgpack:"uuid,omitempty"` // SMBIOS system UUID, readable by superuser only
 }
 
 func (si *SysInfo) getProductInfo() {
-	si.Product.Name = slurpFile("/sys/class/dmi/id/product_name")
-	si.Product.Vendor = slurpFile("/sys/class/dmi/id/sys_vendor")
-	si.Product.Version = slurpFile("/sys/class/dmi/id/product_version")
-	si.Product.Serial = slurpFile("/sys/class/dmi/id/product_serial")
+	si.Product.Name = si.slurpFile("/sys/class/dmi/id/product_name")
+	si.Product.Vendor = si.slurpFile("/sys/class/dmi/id/sys_vendor")
+	si.Product.Version = si.slurpFile("/sys/class/dmi/id/product_version")
+	si.Product.Serial = si.slurpFile("/sys/class/dmi/id/product_serial")
+	si.Product.UUID = si.slurpFile("/sys/class/dmi/id/product_uuid")
 }
@@ -0,0 +1,107 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// String returns human readable, indented summary of all the gathered information. Empty sections and fields are
+// left out, the same way they are omitted from the JSON output.
+func (si SysInfo) String() string {
+	var b strings.Builder
+
+	v := reflect.ValueOf(si)
+	for i := 0; i < v.NumField(); i++ {
+		if field := v.Type().Field(i); field.PkgPath == "" && field.Tag.Get("json") != "-" {
+			renderValue(&b, field.Name, v.Field(i), 0)
+		}
+	}
+
+	return b.String()
+}
+
+func renderValue(b *strings.Builder, label string, v reflect.Value, depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	if v.Type() == timeType {
+		if t := v.Interface().(time.Time); !t.IsZero() {
+			fmt.Fprintf(b, "%s%s: %s\n", indent, label, t.Format(time.RFC3339))
+		}
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.IsZero() {
+			return
+		}
+		fmt.Fprintf(b, "%s%s:\n", indent, label)
+		for i := 0; i < v.NumField(); i++ {
+			if field := v.Type().Field(i); field.PkgPath == "" && field.Tag.Get("json") != "-" {
+				renderValue(b, field.Name, v.Field(i), depth+1)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			return
+		}
+		elem := v.Type().Elem()
+		if elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		if elem.Kind() != reflect.Struct {
+			items := make([]string, v.Len())
+			for i := range items {
+				items[i] = fmt.Sprint(v.Index(i).Interface())
+			}
+			fmt.Fprintf(b, "%s%s: %s\n", indent, label, strings.Join(items, ", "))
+			return
+		}
+		fmt.Fprintf(b, "%s%s:\n", indent, label)
+		for i := 0; i < v.Len(); i++ {
+			// Label list items by their name, where they have one.
+			item := fmt.Sprint(i)
+			if e := reflect.Indirect(v.Index(i)); e.IsValid() {
+				if name := e.FieldByName("Name"); name.IsValid() && name.Kind() == reflect.String && name.String() != "" {
+					item = name.String()
+				}
+			}
+			renderValue(b, item, v.Index(i), depth+1)
+		}
+	case reflect.Map:
+		if v.Len() == 0 {
+			return
+		}
+		keys := make([]string, 0, v.Len())
+		values := make(map[string]reflect.Value, v.Len())
+		for _, k := range v.MapKeys() {
+			key := fmt.Sprint(k.Interface())
+			keys = append(keys, key)
+			values[key] = v.MapIndex(k)
+		}
+		sort.Strings(keys)
+		fmt.Fprintf(b, "%s%s:\n", indent, label)
+		for _, key := range keys {
+			renderValue(b, key, values[key], depth+1)
+		}
+	default:
+		if v.IsZero() {
+			return
+		}
+		fmt.Fprintf(b, "%s%s: %v\n", indent, label, v.Interface())
+	}
+}
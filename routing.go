@@ -0,0 +1,108 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Routing information.
+type Routing struct {
+	DefaultGatewayV4   string `json:"gatewayv4,omitempty" msgpack:"gw4,omitempty"`
+	DefaultInterfaceV4 string `json:"interfacev4,omitempty" msgpack:"if4,omitempty"` // interface carrying the default route
+	DefaultGatewayV6   string `json:"gatewayv6,omitempty" msgpack:"gw6,omitempty"`
+	DefaultInterfaceV6 string `json:"interfacev6,omitempty" msgpack:"if6,omitempty"` // interface carrying the default route
+}
+
+// Route flags from /usr/include/linux/route.h
+const (
+	rtfUp      = 0x0001
+	rtfGateway = 0x0002
+)
+
+// Find the IPv4 default route with the lowest metric.
+func (si *SysInfo) getDefaultRouteV4() {
+	f, err := si.open("/proc/net/route")
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	bestMetric := uint64(1<<64 - 1)
+
+	s := bufio.NewScanner(f)
+	s.Scan() // header
+	for s.Scan() {
+		// Iface Destination Gateway Flags RefCnt Use Metric Mask MTU Window IRTT
+		fields := strings.Fields(s.Text())
+		if len(fields) < 8 || fields[1] != "00000000" || fields[7] != "00000000" {
+			continue
+		}
+
+		flags, _ := strconv.ParseUint(fields[3], 16, 64)
+		metric, _ := strconv.ParseUint(fields[6], 10, 64)
+		if flags&(rtfUp|rtfGateway) != rtfUp|rtfGateway || metric >= bestMetric {
+			continue
+		}
+
+		// Address in network byte order, printed as a (little endian) host integer.
+		gw, err := strconv.ParseUint(fields[2], 16, 32)
+		if err != nil {
+			continue
+		}
+		ip := make(net.IP, net.IPv4len)
+		binary.LittleEndian.PutUint32(ip, uint32(gw))
+
+		bestMetric = metric
+		si.Routing.DefaultGatewayV4 = ip.String()
+		si.Routing.DefaultInterfaceV4 = fields[0]
+	}
+}
+
+// Find the IPv6 default route with the lowest metric.
+func (si *SysInfo) getDefaultRouteV6() {
+	f, err := si.open("/proc/net/ipv6_route")
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	const anyAddr = "00000000000000000000000000000000"
+	bestMetric := uint64(1<<64 - 1)
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		// destination, prefix length, source, prefix length, next hop, metric, refcnt, use, flags, interface
+		fields := strings.Fields(s.Text())
+		if len(fields) < 10 || fields[0] != anyAddr || fields[1] != "00" || fields[4] == anyAddr {
+			continue
+		}
+
+		flags, _ := strconv.ParseUint(fields[8], 16, 64)
+		metric, _ := strconv.ParseUint(fields[5], 16, 64)
+		if flags&rtfUp == 0 || metric >= bestMetric {
+			continue
+		}
+
+		nextHop, err := hex.DecodeString(fields[4])
+		if err != nil || len(nextHop) != net.IPv6len {
+			continue
+		}
+
+		bestMetric = metric
+		si.Routing.DefaultGatewayV6 = net.IP(nextHop).String()
+		si.Routing.DefaultInterfaceV6 = fields[9]
+	}
+}
+
+func (si *SysInfo) getRoutingInfo() {
+	si.getDefaultRouteV4()
+	si.getDefaultRouteV6()
+}
@@ -0,0 +1,119 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+type schemaObject map[string]interface{}
+
+// Schema returns the JSON Schema document describing the JSON encoding of SysInfo. It's generated from the struct
+// definitions, so it always matches the running version of the library. Fields tagged omitempty are optional, all
+// the others are required.
+func Schema() []byte {
+	defs := make(schemaObject)
+
+	schema := typeSchema(sysInfoType, defs)
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = "SysInfo"
+	schema["definitions"] = defs
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil
+	}
+
+	return data
+}
+
+var (
+	sysInfoType = reflect.TypeOf(SysInfo{})
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+func typeSchema(t reflect.Type, defs schemaObject) schemaObject {
+	if t == timeType {
+		return schemaObject{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeSchema(t.Elem(), defs)
+	case reflect.Bool:
+		return schemaObject{"type": "boolean"}
+	case reflect.String:
+		return schemaObject{"type": "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return schemaObject{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return schemaObject{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return schemaObject{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return schemaObject{"type": "array", "items": typeSchema(t.Elem(), defs)}
+	case reflect.Map:
+		return schemaObject{"type": "object", "additionalProperties": typeSchema(t.Elem(), defs)}
+	case reflect.Struct:
+		// Top level type is inlined, all the nested ones are referenced from definitions.
+		if t == sysInfoType {
+			return structSchema(t, defs)
+		}
+		if _, ok := defs[t.Name()]; !ok {
+			defs[t.Name()] = nil // guard against recursion
+			defs[t.Name()] = structSchema(t, defs)
+		}
+		return schemaObject{"$ref": "#/definitions/" + t.Name()}
+	}
+
+	return schemaObject{}
+}
+
+func structSchema(t reflect.Type, defs schemaObject) schemaObject {
+	properties := make(schemaObject)
+	required := make([]string, 0)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		omitempty := false
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+			opts := strings.Split(tag, ",")
+			if opts[0] != "" {
+				name = opts[0]
+			}
+			for _, opt := range opts[1:] {
+				if opt == "omitempty" {
+					omitempty = true
+				}
+			}
+		}
+
+		properties[name] = typeSchema(field.Type, defs)
+		if !omitempty {
+			required = append(required, name)
+		}
+	}
+
+	schema := schemaObject{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
@@ -0,0 +1,173 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package sysinfo
+
+import (
+	"encoding/binary"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// struct sg_io_hdr from /usr/include/scsi/sg.h
+type sgIOHdr struct {
+	InterfaceID    int32
+	DxferDirection int32
+	CmdLen         uint8
+	MxSbLen        uint8
+	IovecCount     uint16
+	DxferLen       uint32
+	Dxferp         uintptr
+	Cmdp           uintptr
+	Sbp            uintptr
+	Timeout        uint32
+	Flags          uint32
+	PackID         int32
+	UsrPtr         uintptr
+	Status         uint8
+	MaskedStatus   uint8
+	MsgStatus      uint8
+	SbLenWr        uint8
+	HostStatus     uint16
+	DriverStatus   uint16
+	Resid          int32
+	Duration       uint32
+	Info           uint32
+}
+
+const (
+	sgIO           = 0x2285 // SG_IO from /usr/include/scsi/sg.h
+	sgDxferFromDev = -3
+	sgTimeout      = 5000 // ms
+)
+
+// SMART attribute names, by attribute ID.
+var smartAttributes = map[byte]string{
+	5:   "reallocated_sectors",
+	9:   "power_on_hours",
+	12:  "power_cycles",
+	177: "wear_leveling_count",
+	190: "airflow_temperature",
+	194: "temperature",
+	196: "reallocation_events",
+	197: "pending_sectors",
+	198: "offline_uncorrectable",
+	199: "udma_crc_errors",
+	231: "ssd_life_left",
+	233: "media_wearout_indicator",
+}
+
+// Issue ATA command through the SCSI ATA PASS-THROUGH (16) command, read one 512 byte sector of data.
+func ataPassThrough(devpath string, command, features, lbaLow, lbaMid, lbaHigh byte) ([]byte, error) {
+	fd, err := unix.Open(devpath, unix.O_RDONLY|unix.O_NONBLOCK, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer unix.Close(fd)
+
+	cdb := [16]byte{
+		0x85,     // ATA PASS-THROUGH (16)
+		4 << 1,   // protocol: PIO data-in
+		0x0e,     // t_dir: from device, byt_blok: blocks, t_length: in sector count
+		0,        // features (15:8)
+		features, // features (7:0)
+		0,        // sector count (15:8)
+		1,        // sector count (7:0)
+		0,        // lba low (15:8)
+		lbaLow,   // lba low (7:0)
+		0,        // lba mid (15:8)
+		lbaMid,   // lba mid (7:0)
+		0,        // lba high (15:8)
+		lbaHigh,  // lba high (7:0)
+		0,        // device
+		command,  // command
+		0,        // control
+	}
+	data := make([]byte, 512)
+	sense := make([]byte, 32)
+
+	hdr := sgIOHdr{
+		InterfaceID:    'S',
+		DxferDirection: sgDxferFromDev,
+		CmdLen:         uint8(len(cdb)),
+		MxSbLen:        uint8(len(sense)),
+		DxferLen:       uint32(len(data)),
+		Dxferp:         uintptr(unsafe.Pointer(&data[0])),
+		Cmdp:           uintptr(unsafe.Pointer(&cdb[0])),
+		Sbp:            uintptr(unsafe.Pointer(&sense[0])),
+		Timeout:        sgTimeout,
+	}
+
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), sgIO, uintptr(unsafe.Pointer(&hdr)))
+	runtime.KeepAlive(data)
+	runtime.KeepAlive(sense)
+	runtime.KeepAlive(&cdb)
+	if errno != 0 {
+		return nil, errno
+	}
+	if hdr.Status != 0 || hdr.HostStatus != 0 || hdr.DriverStatus&^0x08 != 0 { // DRIVER_SENSE is fine
+		return nil, unix.EIO
+	}
+
+	return data, nil
+}
+
+// Read SMART attributes of an ATA disk, as raw values.
+func getSMART(devpath string) map[string]uint64 {
+	// SMART READ DATA
+	data, err := ataPassThrough(devpath, 0xb0, 0xd0, 0, 0x4f, 0xc2)
+	if err != nil {
+		return nil
+	}
+
+	// 30 attributes, 12 bytes each: ID, flags (2), current, worst, raw (6), reserved
+	smart := make(map[string]uint64)
+	for p := 2; p+12 <= 2+30*12; p += 12 {
+		id := data[p]
+		name, ok := smartAttributes[id]
+		if id == 0 || !ok {
+			continue
+		}
+
+		raw := make([]byte, 8)
+		copy(raw, data[p+5:p+11])
+		value := binary.LittleEndian.Uint64(raw)
+
+		switch id {
+		case 9:
+			value &= 0xffffffff // some vendors keep minutes & seconds in the upper bytes
+		case 190, 194:
+			value &= 0xff // upper bytes hold min & max temperature
+		case 177, 231, 233:
+			value = uint64(data[p+3]) // normalized value is the percentage
+		}
+
+		smart[name] = value
+	}
+
+	if len(smart) == 0 {
+		return nil
+	}
+
+	return smart
+}
+
+// Disk supporting the Trusted Computing feature set carries the security protocols (TCG Opal) self-encrypting drives
+// are managed by.
+func isATASelfEncrypting(devpath string) bool {
+	// IDENTIFY DEVICE
+	data, err := ataPassThrough(devpath, 0xec, 0, 0, 0, 0)
+	if err != nil {
+		return false
+	}
+
+	// Word 48: bits 15:14 are 01b when the word is valid, bit 0 is set when the feature set is supported.
+	word := binary.LittleEndian.Uint16(data[96:98])
+	return word&0xc000 == 0x4000 && word&0x1 != 0
+}
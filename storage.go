@@ -2,57 +2,221 @@
 //
 // Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
 
+//go:build linux
+// +build linux
+
 package sysinfo
 
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	diskfs "github.com/diskfs/go-diskfs"
 	"golang.org/x/sys/unix"
-	"io/ioutil"
+	"io/fs"
+	"math"
 	"os"
 	"path"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
-// StorageDevice information.
-type StorageDevice struct {
-	Name          string               `json:"name,omitempty"`
-	Driver        string               `json:"driver,omitempty"`
-	Vendor        string               `json:"vendor,omitempty"`
-	Model         string               `json:"model,omitempty"`
-	Serial        string               `json:"serial,omitempty"`
-	Size          uint                 `json:"size,omitempty"` // device size in MB
-	Partitions    map[string]Partition `json:"partitions,omitempty"`
-	PartitionType string               `json:"partitionType,omitempty"`
+// Unescape octal sequences (\040 for space, etc.) the kernel uses in mount table fields.
+func unescapeMountField(field string) string {
+	if !strings.Contains(field, `\`) {
+		return field
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(field); i++ {
+		if field[i] == '\\' && i+3 < len(field) {
+			if c, err := strconv.ParseUint(field[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(field[i])
+	}
+
+	return b.String()
+}
+
+// Translate mountinfo optional fields to a propagation type, see proc(5).
+func getPropagation(fields []string) string {
+	var prop []string
+	for _, f := range fields {
+		switch {
+		case strings.HasPrefix(f, "shared:"):
+			prop = append(prop, "shared")
+		case strings.HasPrefix(f, "master:"):
+			prop = append(prop, "slave")
+		case f == "unbindable":
+			prop = append(prop, "unbindable")
+		}
+	}
+
+	if len(prop) == 0 {
+		return "private"
+	}
+
+	return strings.Join(prop, ",")
 }
 
-type Partition struct {
-	MountPoint    string `json:"mountPoint,omitempty"`
-	Size          uint   `json:"size,omitempty"`          // partition size in MB
-	AvailableSize uint   `json:"availableSize,omitempty"` // available space in MB
+// Append the superblock options to the mount options, skipping the ones already there, like rw, both of them carry.
+func mergeMountOptions(options, superOptions string) string {
+	seen := make(map[string]bool)
+	opts := strings.Split(options, ",")
+	for _, opt := range opts {
+		seen[opt] = true
+	}
+	for _, opt := range strings.Split(superOptions, ",") {
+		if !seen[opt] {
+			seen[opt] = true
+			opts = append(opts, opt)
+		}
+	}
+
+	return strings.Join(opts, ",")
 }
 
-func getSerial(name, fullpath string) (serial string) {
-	var f *os.File
+// Parse /proc/self/mountinfo, it carries mount IDs, root and propagation info that /proc/mounts lacks.
+func (si *SysInfo) readMountInfo() ([]Mount, error) {
+	f, err := si.open("/proc/self/mountinfo")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var mounts []Mount
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		// 36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+		fields := strings.Fields(s.Text())
+		sep := -1
+		for i := 6; i < len(fields); i++ {
+			if fields[i] == "-" {
+				sep = i
+				break
+			}
+		}
+		if sep < 0 || len(fields) < sep+3 {
+			continue
+		}
+
+		m := Mount{
+			Root:        unescapeMountField(fields[3]),
+			MountPoint:  unescapeMountField(fields[4]),
+			Options:     fields[5],
+			Propagation: getPropagation(fields[6:sep]),
+			FSType:      fields[sep+1],
+			Device:      unescapeMountField(fields[sep+2]),
+		}
+		m.ID, _ = strconv.Atoi(fields[0])
+		m.ParentID, _ = strconv.Atoi(fields[1])
+		if len(fields) > sep+3 {
+			m.Options = mergeMountOptions(m.Options, fields[sep+3])
+		}
+		mounts = append(mounts, m)
+	}
+
+	return mounts, s.Err()
+}
+
+// Parse /proc/mounts, the fallback for minimal systems without /proc/self/mountinfo.
+func (si *SysInfo) readProcMounts() ([]Mount, error) {
+	f, err := si.open("/proc/mounts")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var mounts []Mount
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 4 {
+			continue
+		}
+
+		m := Mount{
+			Root:       "/",
+			Device:     unescapeMountField(fields[0]),
+			MountPoint: unescapeMountField(fields[1]),
+			FSType:     fields[2],
+			Options:    fields[3],
+		}
+		if len(fields) >= 6 {
+			m.Dump, _ = strconv.Atoi(fields[4])
+			m.Pass, _ = strconv.Atoi(fields[5])
+		}
+		mounts = append(mounts, m)
+	}
+
+	return mounts, s.Err()
+}
+
+// ParseMounts returns the mount table of the current process, read from /proc/self/mountinfo, or from /proc/mounts on
+// minimal systems without it.
+func ParseMounts() ([]Mount, error) {
+	var si SysInfo
+	return si.parseMounts()
+}
+
+func (si *SysInfo) parseMounts() ([]Mount, error) {
+	if mounts, err := si.readMountInfo(); err == nil {
+		return mounts, nil
+	}
+
+	return si.readProcMounts()
+}
+
+func (si *SysInfo) readMounts() []Mount {
+	mounts, _ := si.parseMounts()
+	return mounts
+}
+
+func (si *SysInfo) getSerial(name, fullpath string) (serial string) {
+	if c := si.staticCache(); c != nil {
+		if serial, ok := c.serials[name]; ok {
+			return serial
+		}
+		defer func() { c.serials[name] = serial }()
+	}
+
+	return si.getUdevProperty(name, fullpath, "ID_SERIAL_SHORT")
+}
+
+// Look up the property of the block device in the udev database.
+func (si *SysInfo) getUdevProperty(name, fullpath, key string) (value string) {
+	var f fs.File
+	var db string
 	var err error
 
 	// Modern location/format of the udev database.
-	if dev := slurpFile(path.Join(fullpath, "dev")); dev != "" {
-		if f, err = os.Open(path.Join("/run/udev/data", "b"+dev)); err == nil {
+	if dev := si.slurpFile(path.Join(fullpath, "dev")); dev != "" {
+		db = path.Join("/run/udev/data", "b"+dev)
+		if f, err = si.open(db); err == nil {
+			si.tracef("sysinfo: read %s", db)
 			goto scan
 		}
+		si.tracef("sysinfo: %v", err)
 	}
 
 	// Legacy location/format of the udev database.
-	if f, err = os.Open(path.Join("/dev/.udev/db", "block:"+name)); err == nil {
+	db = path.Join("/dev/.udev/db", "block:"+name)
+	if f, err = si.open(db); err == nil {
+		si.tracef("sysinfo: read %s", db)
 		goto scan
 	}
+	si.tracef("sysinfo: %v", err)
 
-	// No serial :(
+	// No udev database :(
 	return
 
 scan:
@@ -61,8 +225,8 @@ scan:
 	s := bufio.NewScanner(f)
 	for s.Scan() {
 		if sl := strings.Split(s.Text(), "="); len(sl) == 2 {
-			if sl[0] == "E:ID_SERIAL_SHORT" {
-				serial = sl[1]
+			if sl[0] == "E:"+key {
+				value = sl[1]
 				break
 			}
 		}
@@ -71,138 +235,986 @@ scan:
 	return
 }
 
-func (si *SysInfo) getStorageInfo() {
-	kbSize := 1000
-	if si.Config.KBSize != 0 {
-		kbSize = si.Config.KBSize
+// Rank /dev/disk/by-id link names, lower is better.
+func byIDRank(link string) int {
+	switch {
+	case strings.HasPrefix(link, "ata-"), strings.HasPrefix(link, "nvme-"):
+		return 0
+	case strings.HasPrefix(link, "wwn-"):
+		return 1
+	case strings.HasPrefix(link, "scsi-"):
+		return 3
 	}
-	sysBlock := "/sys/block"
-	devices, err := ioutil.ReadDir(sysBlock)
+
+	return 2
+}
+
+// Map device names to their preferred persistent name in /dev/disk/by-id.
+func (si *SysInfo) getByIDLinks() map[string]string {
+	const byIDPath = "/dev/disk/by-id"
+
+	byID := make(map[string]string)
+	links, err := si.readDir(byIDPath)
 	if err != nil {
-		return
+		return byID
 	}
 
-	procMounts := "/proc/mounts"
-	var mountsInfo []byte
-	mountsInfo, err = ioutil.ReadFile(procMounts)
-	if err != nil {
+	for _, link := range links {
+		target, err := si.readlink(path.Join(byIDPath, link.Name()))
+		if err != nil {
+			continue
+		}
+
+		name := path.Base(target)
+		if cur, ok := byID[name]; ok {
+			if byIDRank(link.Name()) > byIDRank(cur) || (byIDRank(link.Name()) == byIDRank(cur) && link.Name() > cur) {
+				continue
+			}
+		}
+		byID[name] = link.Name()
+	}
+
+	return byID
+}
+
+// Get multipath info of a device-mapper device, nil for all the other devices.
+func (si *SysInfo) getMultipath(fullpath string) *MultipathInfo {
+	uuid := si.slurpFile(path.Join(fullpath, "dm", "uuid"))
+	if !strings.HasPrefix(uuid, "mpath-") {
+		return nil
+	}
+
+	multipath := &MultipathInfo{
+		Name:  si.slurpFile(path.Join(fullpath, "dm", "name")),
+		WWID:  strings.TrimPrefix(uuid, "mpath-"),
+		Paths: make([]string, 0),
+	}
+	if slaves, err := si.readDir(path.Join(fullpath, "slaves")); err == nil {
+		for _, slave := range slaves {
+			multipath.Paths = append(multipath.Paths, slave.Name())
+		}
+	}
+
+	return multipath
+}
+
+// Tunables of the active I/O scheduler, they differ between schedulers (mq-deadline, bfq, kyber).
+func (si *SysInfo) getSchedulerParams(fullpath string) map[string]string {
+	iosched := path.Join(fullpath, "queue", "iosched")
+	entries, err := si.readDir(iosched)
+	if err != nil || len(entries) == 0 {
+		return nil
+	}
+
+	params := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if value := si.slurpFile(path.Join(iosched, entry.Name())); value != "" {
+			params[entry.Name()] = value
+		}
+	}
+
+	return params
+}
+
+// Names of the devices owning the bcache directories linked from the cache set, like cache0 or bdev0.
+func (si *SysInfo) getCacheSetMembers(setpath, prefix string) []string {
+	var members []string
+	if entries, err := si.readDir(setpath); err == nil {
+		for _, entry := range entries {
+			suffix := strings.TrimPrefix(entry.Name(), prefix)
+			if suffix == entry.Name() || suffix == "" || strings.Trim(suffix, "0123456789") != "" {
+				continue
+			}
+			if target, err := si.evalSymlinks(path.Join(setpath, entry.Name())); err == nil {
+				members = append(members, path.Base(path.Dir(target)))
+			}
+		}
+	}
+
+	return members
+}
+
+// Detect bcache role of the device. Backing device links to its bcacheN device and the cache set, cache device links
+// only to the cache set, and bcacheN device lists its backing device among the slaves.
+func (si *SysInfo) getBcache(sysBlock, name string) *BcacheInfo {
+	if strings.HasPrefix(name, "bcache") {
+		slaves, err := si.readDir(path.Join(sysBlock, name, "slaves"))
+		if err != nil || len(slaves) == 0 {
+			return nil
+		}
+		backing := slaves[0].Name()
+		return &BcacheInfo{
+			Role:    "bcache",
+			Backing: []string{backing},
+			Cache:   si.getCacheSetMembers(path.Join(sysBlock, backing, "bcache", "cache"), "cache"),
+		}
+	}
+
+	bcpath := path.Join(sysBlock, name, "bcache")
+	if _, err := si.stat(bcpath); err != nil {
+		return nil
+	}
+
+	setpath := path.Join(bcpath, "set")
+	if _, err := si.stat(setpath); err == nil {
+		return &BcacheInfo{
+			Role:    "cache",
+			Backing: si.getCacheSetMembers(setpath, "bdev"),
+		}
+	}
+
+	bcache := &BcacheInfo{
+		Role:  "backing",
+		Cache: si.getCacheSetMembers(path.Join(bcpath, "cache"), "cache"),
+	}
+	if dev, err := si.evalSymlinks(path.Join(bcpath, "dev")); err == nil {
+		bcache.Device = path.Base(dev)
+	}
+
+	return bcache
+}
+
+// Read md array state, from the md directory only arrays have. Sync progress is given in sectors, like 1234 / 5678,
+// or none, when there's no sync action running.
+func (si *SysInfo) getRAID(fullpath string) *RAIDInfo {
+	mdpath := path.Join(fullpath, "md")
+	if _, err := si.stat(mdpath); err != nil {
+		return nil
+	}
+
+	raid := &RAIDInfo{
+		Level:      si.slurpFile(path.Join(mdpath, "level")),
+		State:      si.slurpFile(path.Join(mdpath, "array_state")),
+		SyncAction: si.slurpFile(path.Join(mdpath, "sync_action")),
+	}
+
+	var inSync uint64
+	if members, err := si.glob(path.Join(mdpath, "dev-*")); err == nil {
+		for _, member := range members {
+			name := strings.TrimPrefix(path.Base(member), "dev-")
+			raid.Members = append(raid.Members, name)
+
+			// Comma separated list of flags, like in_sync,write_mostly.
+			for _, flag := range strings.Split(si.slurpFile(path.Join(member, "state")), ",") {
+				switch flag {
+				case "faulty":
+					raid.Failed = append(raid.Failed, name)
+				case "in_sync":
+					inSync++
+				}
+			}
+		}
+		sort.Strings(raid.Members)
+		sort.Strings(raid.Failed)
+	}
+
+	// Number of missing members, older kernels don't have it, so count members in sync against the slots.
+	if degraded, err := strconv.ParseUint(si.slurpFile(path.Join(mdpath, "degraded")), 10, 64); err == nil {
+		raid.Degraded = degraded > 0
+	} else if slots, err := strconv.ParseUint(si.slurpFile(path.Join(mdpath, "raid_disks")), 10, 64); err == nil {
+		raid.Degraded = inSync < slots
+	}
+
+	if raid.SyncAction != "" && raid.SyncAction != "idle" {
+		var done, total float64
+		completed := si.slurpFile(path.Join(mdpath, "sync_completed"))
+		if n, _ := fmt.Sscanf(completed, "%f / %f", &done, &total); n == 2 && total > 0 {
+			raid.SyncPercent = math.Round(done/total*1000) / 10
+		}
+		if speed, err := strconv.ParseUint(si.slurpFile(path.Join(mdpath, "sync_speed")), 10, 64); err == nil {
+			raid.SyncSpeed = uint(speed)
+		}
+	}
+
+	return raid
+}
+
+// Find the partition & disk the filesystem holding the path lives on, resolving device-mapper devices (LVM, dm-crypt)
+// down through their slaves.
+func (si *SysInfo) getMountDevice(mounts []Mount, mountPoint string) (disk, partition string) {
+	var name string
+	for _, m := range mounts {
+		if m.MountPoint == mountPoint && m.Root == "/" {
+			if dev, err := si.evalSymlinks(m.Device); err == nil && strings.HasPrefix(dev, "/dev/") {
+				name = path.Base(dev)
+			}
+		}
+	}
+
+	// Like /dev/root, that doesn't really exist, ask the kernel what's mounted instead, unless reading a copy of the
+	// system files.
+	if name == "" && si.Config.FS == nil {
+		var stat unix.Stat_t
+		if err := unix.Stat(mountPoint, &stat); err != nil {
+			return
+		}
+		dev, err := si.readlink(fmt.Sprintf("/sys/dev/block/%d:%d", unix.Major(uint64(stat.Dev)), unix.Minor(uint64(stat.Dev))))
+		if err != nil {
+			return
+		}
+		name = path.Base(dev)
+	}
+	if name == "" {
 		return
 	}
-	partmounts := make(map[string]string)
-	s := bufio.NewScanner(bytes.NewBuffer(mountsInfo))
+
+	sysClassBlock := "/sys/class/block"
 	for {
-		if s.Scan() {
-			line := s.Text()
-			if strings.Index(line, "/dev/") == 0 {
-				mountinfo := strings.Split(line, " ")
-				_, exist := partmounts[mountinfo[0]]
-				if !exist {
-					partmounts[mountinfo[0]] = mountinfo[1]
+		slaves, err := si.readDir(path.Join(sysClassBlock, name, "slaves"))
+		if err != nil || len(slaves) == 0 {
+			break
+		}
+		name = slaves[0].Name()
+	}
+
+	if _, err := si.stat(path.Join(sysClassBlock, name, "partition")); err != nil {
+		return name, ""
+	}
+
+	partition = name
+	if dev, err := si.evalSymlinks(path.Join(sysClassBlock, name)); err == nil {
+		disk = path.Base(path.Dir(dev))
+	}
+
+	return
+}
+
+func (si *SysInfo) getRootDevice(mounts []Mount) {
+	si.OS.RootDevice, si.OS.RootPartition = si.getMountDevice(mounts, "/")
+}
+
+// Disk the system booted from, the one holding EFI system partition on UEFI systems, otherwise the one holding /boot,
+// which is the root disk when /boot isn't a filesystem of its own.
+func (si *SysInfo) getBootDevice(mounts []Mount) {
+	if _, err := si.stat("/sys/firmware/efi"); err == nil {
+		for _, esp := range []string{"/boot/efi", "/efi", "/boot"} {
+			for _, m := range mounts {
+				if m.MountPoint == esp && m.FSType == "vfat" {
+					si.OS.BootDevice, _ = si.getMountDevice(mounts, esp)
+					return
 				}
 			}
-		} else {
-			break
 		}
 	}
 
+	si.OS.BootDevice, _ = si.getMountDevice(mounts, "/boot")
+}
+
+// Get negotiated link speed of ATA device, like 6.0 Gbps. The device path goes through the ATA port (ata1), and the
+// disk's SCSI address (0:0:0:0) tells the port multiplier port in the channel. Links are named by the ATA port, like
+// link1, and the port multiplier port, like link1.2, if there is one.
+func (si *SysInfo) getATALinkSpeed(dev string) string {
+	var port, channel string
+	for _, elem := range strings.Split(dev, "/") {
+		if strings.HasPrefix(elem, "ata") {
+			if _, err := strconv.Atoi(elem[3:]); err == nil {
+				port = elem[3:]
+			}
+		}
+		if addr := strings.Split(elem, ":"); len(addr) == 4 && port != "" {
+			channel = addr[1]
+		}
+	}
+	if port == "" {
+		return ""
+	}
+
+	links := []string{"link" + port}
+	if channel != "" {
+		links = append([]string{"link" + port + "." + channel}, links...)
+	}
+	for _, link := range links {
+		speed := si.slurpFile(path.Join("/sys/class/ata_link", link, "sata_spd"))
+		if speed != "" && speed != "<unknown>" {
+			return speed
+		}
+	}
+
+	return ""
+}
+
+// PCIe transfer rates (GT/s) by generation
+var pcieGens = map[string]uint{"2.5": 1, "5.0": 2, "5": 2, "8.0": 3, "8": 3, "16.0": 4, "16": 4, "32.0": 5, "32": 5, "64.0": 6, "64": 6}
+
+// Get negotiated PCIe link of NVMe device. The block device's parent is the NVMe controller, whose parent is the PCI
+// function.
+func (si *SysInfo) getPCIeLink(fullpath string) (gen, width uint) {
+	pcipath := path.Join(fullpath, "device", "device")
+
+	// 8.0 GT/s PCIe
+	if fields := strings.Fields(si.slurpFile(path.Join(pcipath, "current_link_speed"))); len(fields) > 0 {
+		gen = pcieGens[fields[0]]
+	}
+
+	if w, err := strconv.ParseUint(si.slurpFile(path.Join(pcipath, "current_link_width")), 10, 64); err == nil {
+		width = uint(w)
+	}
+
+	return
+}
+
+// State shared by all the storage devices being collected.
+type storageScan struct {
+	kbSize     int
+	partmounts map[string][]Mount
+	partsizes  map[string]string
+	byID       map[string]string
+	multipaths map[string]*MultipathInfo
+	mpathPaths map[string]bool
+	dmNames    map[string]string  // kernel names of device-mapper devices by their names
+	usage      map[string]FSUsage // filesystem usage by mount point
+}
+
+// Resolve the symbolic links mount source is recorded by, like /dev/disk/by-uuid/..., to the device node, for it
+// to match the device it's on. Device-mapper names are resolved to the kernel names, like dm-0.
+func (si *SysInfo) canonicalMountDevice(scan *storageScan, dev string) string {
+	if dmName := strings.TrimPrefix(dev, "/dev/mapper/"); dmName != dev {
+		if kname, ok := scan.dmNames[dmName]; ok {
+			return "/dev/" + kname
+		}
+	}
+	if resolved, err := si.evalSymlinks(dev); err == nil && strings.HasPrefix(resolved, "/dev/") {
+		return resolved
+	}
+
+	return dev
+}
+
+func (si *SysInfo) newStorageScan(names []string, mounts []Mount) (*storageScan, error) {
+	scan := &storageScan{
+		kbSize:     si.kbSize(),
+		partmounts: make(map[string][]Mount),
+		partsizes:  make(map[string]string),
+		byID:       si.getByIDLinks(),
+		multipaths: make(map[string]*MultipathInfo),
+		mpathPaths: make(map[string]bool),
+		dmNames:    make(map[string]string),
+	}
+
+	if dms, err := si.glob("/sys/block/dm-*"); err == nil {
+		for _, dm := range dms {
+			if dmName := si.slurpFile(path.Join(dm, "dm", "name")); dmName != "" {
+				scan.dmNames[dmName] = path.Base(dm)
+			}
+		}
+	}
+
+	var mountPoints []string
+	for _, m := range mounts {
+		if strings.Index(m.Device, "/dev/") == 0 {
+			dev := si.canonicalMountDevice(scan, m.Device)
+			scan.partmounts[dev] = append(scan.partmounts[dev], m)
+			mountPoints = append(mountPoints, m.MountPoint)
+			continue
+		}
+		switch m.FSType {
+		case "overlay":
+			mountPoints = append(mountPoints, m.MountPoint)
+		case "tmpfs", "ramfs":
+			if si.Config.IncludeTmpfs {
+				mountPoints = append(mountPoints, m.MountPoint)
+			}
+		}
+	}
+	scan.usage = si.statfsAll(mountPoints)
+
 	procParts := "/proc/partitions"
-	var partsInfo []byte
-	partsInfo, err = ioutil.ReadFile(procParts)
-	mountsInfo, err = ioutil.ReadFile(procMounts)
+	partsInfo, err := si.readFile(procParts)
 	if err != nil {
-		return
+		return nil, err
 	}
-	partsizes := make(map[string]string)
-	s = bufio.NewScanner(bytes.NewBuffer(partsInfo))
+	s := bufio.NewScanner(bytes.NewBuffer(partsInfo))
 	for {
 		if s.Scan() {
-			line := s.Text()
-			regex := regexp.MustCompile(`\w+`)
-			partinfo := regex.FindAllString(line, -1)
+			// Not split on non-word characters, names like dm-0 have them.
+			partinfo := strings.Fields(s.Text())
 			if len(partinfo) == 4 {
-				partsizes[partinfo[3]] = partinfo[2]
+				scan.partsizes[partinfo[3]] = partinfo[2]
 			}
 		} else {
 			break
 		}
 	}
 
-	si.Storage = make([]StorageDevice, 0)
+	// Paths of multipath devices are reported as part of the multipath device, not to count the same LUN many times.
+	for _, name := range names {
+		if multipath := si.getMultipath(path.Join("/sys/block", name)); multipath != nil {
+			scan.multipaths[name] = multipath
+			for _, p := range multipath.Paths {
+				scan.mpathPaths[p] = true
+			}
+		}
+	}
+
+	return scan, nil
+}
+
+const (
+	defaultStatfsConcurrency = 4
+	statfsTimeout            = 5 * time.Second
+)
+
+// Get usage of all the filesystems mounted at the mount points, a few at a time, as configured. Every worker fills
+// only its own slots, so there's nothing shared to lock until all of them are done. Filesystems that haven't answered
+// in time, like hung network mounts, are left out.
+func (si *SysInfo) statfsAll(mountPoints []string) map[string]FSUsage {
+	workers := si.Config.StatfsConcurrency
+	if workers <= 0 {
+		workers = defaultStatfsConcurrency
+	}
+
+	usage := make([]*FSUsage, len(mountPoints))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				ctx, cancel := context.WithTimeout(context.Background(), statfsTimeout)
+				if u, err := si.statfsContext(ctx, mountPoints[i]); err == nil {
+					usage[i] = &u
+				} else {
+					si.tracef("sysinfo: statfs %s: %v", mountPoints[i], err)
+				}
+				cancel()
+			}
+		}()
+	}
+	for i := range mountPoints {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	result := make(map[string]FSUsage, len(mountPoints))
+	for i, u := range usage {
+		if u != nil {
+			result[mountPoints[i]] = *u
+		}
+	}
+
+	return result
+}
+
+// Statfs(2) can't be interrupted, so if the context is done first, the call is abandoned to finish in the background.
+func (si *SysInfo) statfsContext(ctx context.Context, name string) (FSUsage, error) {
+	type result struct {
+		usage FSUsage
+		err   error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		usage, err := si.statfs(name)
+		done <- result{usage, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.usage, r.err
+	case <-ctx.Done():
+		return FSUsage{}, ctx.Err()
+	}
+}
+
+func (si *SysInfo) getStorageInfo() {
+	sysBlock := "/sys/block"
+	devices, err := si.readDir(sysBlock)
+	if err != nil {
+		return
+	}
+
+	mounts := si.readMounts()
+	si.getRootDevice(mounts)
+	si.getBootDevice(mounts)
+
+	names := make([]string, 0, len(devices))
 	for _, link := range devices {
-		name := link.Name()
+		names = append(names, link.Name())
+	}
+
+	scan, err := si.newStorageScan(names, mounts)
+	if err != nil {
+		return
+	}
+
+	si.Storage = make([]StorageDevice, 0)
+	for _, name := range names {
 		fullpath := path.Join(sysBlock, name)
-		dev, err := os.Readlink(fullpath)
+		dev, err := si.readlink(fullpath)
 		if err != nil {
 			continue
 		}
 
-		if strings.HasPrefix(dev, "../devices/virtual/") {
+		// Stacked virtual devices of interest, multipath, bcache and md arrays, are reported alongside the disks.
+		multipath := scan.multipaths[name]
+		bcache := si.getBcache(sysBlock, name)
+		raid := si.getRAID(fullpath)
+		stacked := multipath != nil || bcache != nil || raid != nil
+		if (strings.HasPrefix(dev, "../devices/virtual/") && !stacked) || scan.mpathPaths[name] {
 			continue
 		}
 
 		// We could filter all removable devices here, but some systems boot from USB flash disks, and then we
-		// would filter them, too. So, let's filter only floppies and CD/DVD devices, and see how it pans out.
-		if strings.HasPrefix(dev, "../devices/platform/floppy") || slurpFile(path.Join(fullpath, "device", "type")) == "5" {
+		// would filter them, too. So, let's filter only floppies and CD/DVD devices (unless requested), and see how
+		// it pans out.
+		optical := si.slurpFile(path.Join(fullpath, "device", "type")) == "5"
+		if strings.HasPrefix(dev, "../devices/platform/floppy") || (optical && !si.Config.IncludeOptical) {
+			continue
+		}
+
+		device := si.getStorageDevice(scan, name, dev, bcache, raid)
+		if si.Config.BuildTree {
+			device.Children = si.getHolders(scan, name, map[string]bool{name: true})
+		}
+		si.Storage = append(si.Storage, device)
+	}
+
+	// Overlay mounts are not backed by a block device, collect them under a synthetic storage device.
+	if parts := si.getFSTypePartitions(scan, mounts, "overlay"); len(parts) > 0 {
+		si.Storage = append(si.Storage, StorageDevice{
+			Name:       "overlay",
+			Partitions: parts,
+		})
+	}
+
+	// Same goes for the memory backed filesystems, on request.
+	if si.Config.IncludeTmpfs {
+		for _, fsType := range []string{"tmpfs", "ramfs"} {
+			if parts := si.getFSTypePartitions(scan, mounts, fsType); len(parts) > 0 {
+				si.Storage = append(si.Storage, StorageDevice{
+					Name:       fsType,
+					Partitions: parts,
+				})
+			}
+		}
+	}
+}
+
+// Collect devices stacked on top of the device or any of its partitions (device-mapper, md, bcache), recursively, the
+// way lsblk shows them.
+func (si *SysInfo) getHolders(scan *storageScan, name string, seen map[string]bool) []StorageDevice {
+	fullpath := path.Join("/sys/class/block", name)
+	holderDirs, _ := si.glob(path.Join(fullpath, name+"*", "holders"))
+	holderDirs = append([]string{path.Join(fullpath, "holders")}, holderDirs...)
+
+	var children []StorageDevice
+	for _, dir := range holderDirs {
+		holders, err := si.readDir(dir)
+		if err != nil {
 			continue
 		}
+		for _, holder := range holders {
+			if seen[holder.Name()] {
+				continue
+			}
+			seen[holder.Name()] = true
+
+			dev, err := si.readlink(path.Join("/sys/block", holder.Name()))
+			if err != nil {
+				continue
+			}
+			bcache := si.getBcache("/sys/block", holder.Name())
+			raid := si.getRAID(path.Join("/sys/block", holder.Name()))
+			child := si.getStorageDevice(scan, holder.Name(), dev, bcache, raid)
+			child.Children = si.getHolders(scan, holder.Name(), seen)
+			children = append(children, child)
+		}
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].Name < children[j].Name })
+
+	return children
+}
+
+// GetStorageDevice gathers information about a single storage device, by its name in /sys/block (like sdb), without
+// collecting anything else. The device is reported even if GetSysInfo would filter it out (virtual, optical).
+func GetStorageDevice(name string, cfg Config) (StorageDevice, error) {
+	si := SysInfo{Config: cfg}
+
+	dev, err := si.readlink(path.Join("/sys/block", name))
+	if err != nil || strings.Contains(name, "/") {
+		return StorageDevice{}, fmt.Errorf("storage device %s: %w", name, os.ErrNotExist)
+	}
+
+	scan, err := si.newStorageScan([]string{name}, si.readMounts())
+	if err != nil {
+		return StorageDevice{}, err
+	}
 
-		device := StorageDevice{
-			Name:   name,
-			Model:  slurpFile(path.Join(fullpath, "device", "model")),
-			Serial: getSerial(name, fullpath),
+	bcache := si.getBcache("/sys/block", name)
+	raid := si.getRAID(path.Join("/sys/block", name))
+	return si.getStorageDevice(scan, name, dev, bcache, raid), nil
+}
+
+// Collect the block device, dev is its /sys/block link target. Its bcache & md RAID info is passed in, as the callers
+// need it first to tell whether the device is of interest.
+func (si *SysInfo) getStorageDevice(scan *storageScan, name, dev string, bc *BcacheInfo, raid *RAIDInfo) StorageDevice {
+	sysBlock := "/sys/block"
+	fullpath := path.Join(sysBlock, name)
+	kbSize := scan.kbSize
+	multipath := scan.multipaths[name]
+	optical := si.slurpFile(path.Join(fullpath, "device", "type")) == "5"
+
+	device := StorageDevice{
+		Name:       name,
+		Model:      si.slurpFile(path.Join(fullpath, "device", "model")),
+		Firmware:   si.slurpFile(path.Join(fullpath, "device", "rev")),
+		Serial:     si.getSerial(name, fullpath),
+		SysPath:    path.Join(sysBlock, dev),
+		ByID:       scan.byID[name],
+		Multipath:  multipath,
+		Bcache:     bc,
+		RAID:       raid,
+		NUMANode:   si.getNUMANode(path.Join(fullpath, "device")),
+		WriteCache: si.slurpFile(path.Join(fullpath, "queue", "write_cache")),
+		Zoned:      si.slurpFile(path.Join(fullpath, "queue", "zoned")),
+	}
+	devpath := fmt.Sprintf("/dev/%s", device.Name)
+
+	if disk, err := diskfs.OpenWithMode(devpath, diskfs.ReadOnly); err == nil {
+		if pt, err := disk.GetPartitionTable(); err == nil {
+			device.PartitionType = pt.Type()
 		}
-		devpath := fmt.Sprintf("/dev/%s", device.Name)
+	}
+
+	if driver, err := si.readlink(path.Join(fullpath, "device", "driver")); err == nil {
+		device.Driver = path.Base(driver)
+	}
 
-		if disk, err := diskfs.OpenWithMode(devpath, diskfs.ReadOnly); err == nil {
-			if pt, err := disk.GetPartitionTable(); err == nil {
-				device.PartitionType = pt.Type()
+	if vendor := si.slurpFile(path.Join(fullpath, "device", "vendor")); !strings.HasPrefix(vendor, "0x") {
+		device.Vendor = vendor
+	}
+
+	if strings.HasPrefix(name, "nvme") {
+		device.Firmware = si.slurpFile(path.Join(fullpath, "device", "firmware_rev"))
+		device.PCIeGen, device.PCIeWidth = si.getPCIeLink(fullpath)
+	}
+	device.LinkSpeed = si.getATALinkSpeed(dev)
+
+	if si.Config.EnableSMART {
+		switch {
+		case strings.HasPrefix(name, "sd"):
+			device.SelfEncrypting = isATASelfEncrypting(devpath)
+			device.SMART = getSMART(devpath)
+			device.PowerOnHours = uint(device.SMART["power_on_hours"])
+			for _, attr := range []string{"wear_leveling_count", "media_wearout_indicator", "ssd_life_left"} {
+				// Normalized value counts down from 100.
+				if life, ok := device.SMART[attr]; ok && life <= 100 {
+					device.WearLevelPercent = uint(100 - life)
+					break
+				}
+			}
+		case strings.HasPrefix(name, "nvme"):
+			device.SelfEncrypting = isNVMeSelfEncrypting(devpath)
+			if log, err := getNVMeSMARTLog(devpath); err == nil {
+				device.PowerOnHours = uint(log.PowerOnHours)
+				device.WearLevelPercent = log.PercentageUsed
+				device.CriticalWarnings = log.criticalWarnings()
 			}
 		}
+	}
 
-		if driver, err := os.Readlink(path.Join(fullpath, "device", "driver")); err == nil {
-			device.Driver = path.Base(driver)
+	// Multipath device itself has no hardware info, take it from one of its paths.
+	if multipath != nil {
+		if len(multipath.Paths) > 0 {
+			pathpath := path.Join(sysBlock, multipath.Paths[0])
+			device.Vendor = si.slurpFile(path.Join(pathpath, "device", "vendor"))
+			device.Model = si.slurpFile(path.Join(pathpath, "device", "model"))
+			device.Serial = si.getSerial(multipath.Paths[0], pathpath)
 		}
+	}
+
+	device.Scheduler = activeChoice(si.slurpFile(path.Join(fullpath, "queue", "scheduler")))
+	device.SchedulerParams = si.getSchedulerParams(fullpath)
 
-		if vendor := slurpFile(path.Join(fullpath, "device", "vendor")); !strings.HasPrefix(vendor, "0x") {
-			device.Vendor = vendor
+	if depth, err := strconv.ParseUint(si.slurpFile(path.Join(fullpath, "device", "queue_depth")), 10, 64); err == nil {
+		device.QueueDepth = uint(depth)
+	}
+	if nr, err := strconv.ParseUint(si.slurpFile(path.Join(fullpath, "queue", "nr_requests")), 10, 64); err == nil {
+		device.NrRequests = uint(nr)
+	}
+	// blk-mq devices have a directory per hardware queue.
+	if queues, err := si.readDir(path.Join(fullpath, "mq")); err == nil {
+		device.MultiQueue = true
+		for _, queue := range queues {
+			if queue.IsDir() {
+				device.HardwareQueues++
+			}
 		}
+	}
+	if minIO, err := strconv.ParseUint(si.slurpFile(path.Join(fullpath, "queue", "minimum_io_size")), 10, 64); err == nil {
+		device.MinimumIOSize = uint(minIO)
+	}
+	if optIO, err := strconv.ParseUint(si.slurpFile(path.Join(fullpath, "queue", "optimal_io_size")), 10, 64); err == nil {
+		device.OptimalIOSize = uint(optIO)
+	}
 
-		size, _ := strconv.ParseUint(slurpFile(path.Join(fullpath, "size")), 10, 64)
-		device.Size = uint(size * 512 / (uint64(kbSize) * uint64(kbSize))) // MiB
-		parts := make(map[string]Partition)
-		for part, mp := range partmounts {
-			if strings.Index(part, devpath) == 0 {
-				partName := part[5:]
-				var psize uint
-				sizeStr, ok := partsizes[partName]
-				if ok {
-					size, _ := strconv.ParseUint(sizeStr, 10, 64)
-					psize = uint(size * 1024 / uint64(kbSize) / uint64(kbSize))
+	size, _ := strconv.ParseUint(si.slurpFile(path.Join(fullpath, "size")), 10, 64)
+	device.Size = uint(size * 512 / (uint64(kbSize) * uint64(kbSize))) // MiB
+	// Kernel counts the size in 512 byte sectors, whatever the logical block size of the device is.
+	device.ExactBytes = size * 512
+	if optical {
+		device.OpticalMediaPresent = size > 0
+	}
+	var parts []Partition
+	partSize := func(partName string) uint {
+		size, _ := strconv.ParseUint(scan.partsizes[partName], 10, 64)
+		return uint(size * 1024 / uint64(kbSize) / uint64(kbSize))
+	}
+	partType := func(partName string) string {
+		partPath := fullpath
+		if partName != name {
+			partPath = path.Join(fullpath, partName)
+			if _, err := si.stat(partPath); err != nil {
+				partPath = path.Join(sysBlock, partName)
+			}
+		}
+		return si.getPartType(partName, partPath)
+	}
+	mounted := make(map[string]bool)
+	for part, mps := range scan.partmounts {
+		if partName, ok := si.partitionOf(name, fullpath, part); ok {
+			mounted[partName] = true
+			psize := partSize(partName)
+			ptype := partType(partName)
+			var sb *extSuperblock
+			if si.Config.ReadSuperblocks && si.Config.FS == nil && isExtFS(mps[0].FSType) {
+				sb, _ = readExtSuperblock(part)
+			}
+			seen := make(map[string]bool)
+			for _, mp := range mps {
+				// Every mount point gets its own entry. The root of the mount is "/" for the partition's own
+				// mount, anything else is a bind mount of a subtree. Stacked mounts on the same mount point are
+				// reported once.
+				if seen[mp.MountPoint] {
+					continue
 				}
+				seen[mp.MountPoint] = true
 				partition := Partition{
-					MountPoint: mp,
-					Size:       psize,
+					Name:         partName,
+					MountPoint:   mp.MountPoint,
+					Size:         psize,
+					PartType:     ptype,
+					Propagation:  mp.Propagation,
+					QuotaEnabled: hasQuota(mp.Options),
+				}
+				if mp.Root != "/" {
+					partition.BindSource = mp.Root
+				}
+				if usage, ok := scan.usage[mp.MountPoint]; ok {
+					partition.AvailableSize = uint(usage.Available / 1024 / 1024)
+					partition.ReservedPercent = reservedPercent(usage.Size, usage.Free, usage.Available)
 				}
-				asize, err := diskUsage(mp)
-				if err == nil {
-					partition.AvailableSize = uint(asize / 1024 / 1024)
+				if sb != nil {
+					if !sb.mtime.IsZero() {
+						lastMount := sb.mtime
+						partition.LastMount = &lastMount
+					}
+					partition.NeedsCheck = sb.needsCheck()
+					partition.FSFeatures = sb.features()
 				}
-				parts[partName] = partition
+				parts = append(parts, partition)
+			}
+		}
+	}
+	// Partitions that aren't mounted anywhere, like the ESP or recovery partitions, are listed without the mount
+	// point.
+	if entries, err := si.readDir(fullpath); err == nil {
+		for _, entry := range entries {
+			partName := entry.Name()
+			if mounted[partName] || !strings.HasPrefix(partName, name) {
+				continue
+			}
+			if _, err := si.stat(path.Join(fullpath, partName, "partition")); err != nil {
+				continue
+			}
+			parts = append(parts, Partition{
+				Name:     partName,
+				Size:     partSize(partName),
+				PartType: partType(partName),
+			})
+		}
+	}
+	if len(parts) > 0 {
+		sortPartitions(parts)
+		device.Partitions = parts
+	}
+
+	return device
+}
+
+// Partition roles by the GPT partition type GUID, or the MBR partition type.
+var partTypes = map[string]string{
+	"c12a7328-f81f-11d2-ba4b-00a0c93ec93b": "ESP",
+	"21686148-6449-6e6f-744e-656564454649": "bios-boot",
+	"0657fd6d-a4ab-43c4-84e5-0933c84b4f4f": "linux-swap",
+	"0fc63daf-8483-4772-8e79-3d69d8477de4": "linux-filesystem",
+	"4f68bce3-e8cd-4db1-96e7-fbcaf984b709": "linux-root",
+	"44479540-f297-41b2-9af7-d131d5f0458a": "linux-root",
+	"b921b045-1df0-41c3-af44-4c6f280d3fae": "linux-root",
+	"933ac7e1-2eb4-4f13-b844-0e14e2aef915": "linux-home",
+	"bc13c2ff-59e6-4262-a352-b275fd6f7172": "linux-boot",
+	"e6d6d379-f507-44c2-a23c-238f2a3df928": "linux-lvm",
+	"a19d880f-05fc-4d3b-a006-743f0f84911e": "linux-raid",
+	"ca7d7ccb-63ed-4c53-861c-1742536059cc": "linux-luks",
+	"ebd0a0a2-b9e5-4433-87c0-68b6b72699c7": "microsoft-basic-data",
+	"e3c9e316-0b5c-4db8-817d-f92df00215ae": "microsoft-reserved",
+	"de94bba4-06d1-4d40-a16a-bfd50179d6ac": "microsoft-recovery",
+	"0xef":                                 "ESP",
+	"0x82":                                 "linux-swap",
+	"0x83":                                 "linux-filesystem",
+	"0x8e":                                 "linux-lvm",
+	"0xfd":                                 "linux-raid",
+	"0x27":                                 "microsoft-recovery",
+}
+
+// Role of the partition by its type, as udev knows it. Unknown types are reported as they are.
+func (si *SysInfo) getPartType(name, fullpath string) string {
+	partType := strings.ToLower(si.getUdevProperty(name, fullpath, "ID_PART_ENTRY_TYPE"))
+	if role, ok := partTypes[partType]; ok {
+		return role
+	}
 
+	return partType
+}
+
+// Collect all mounts of the given filesystem type, named by the mount point.
+func (si *SysInfo) getFSTypePartitions(scan *storageScan, mounts []Mount, fsType string) []Partition {
+	kbSize := scan.kbSize
+
+	var parts []Partition
+	for _, m := range mounts {
+		if m.FSType != fsType {
+			continue
+		}
+
+		partition := Partition{
+			Name:         m.MountPoint,
+			MountPoint:   m.MountPoint,
+			Propagation:  m.Propagation,
+			QuotaEnabled: hasQuota(m.Options),
+		}
+		if usage, ok := scan.usage[m.MountPoint]; ok {
+			partition.Size = uint(usage.Size / uint64(kbSize) / uint64(kbSize))
+			partition.AvailableSize = uint(usage.Available / uint64(kbSize) / uint64(kbSize))
+			if fsType == "tmpfs" || fsType == "ramfs" {
+				partition.UsedSize = uint((usage.Size - usage.Available) / uint64(kbSize) / uint64(kbSize))
 			}
 		}
-		if len(parts) > 0 {
-			device.Partitions = parts
+		switch fsType {
+		case "tmpfs", "ramfs":
+			// Prefer the configured size limit, if any.
+			if size, ok := parseMountSize(mountOption(m.Options, "size")); ok {
+				partition.Size = uint(size / uint64(kbSize) / uint64(kbSize))
+			}
+		case "overlay":
+			partition.Overlay = &OverlayInfo{
+				LowerDir: mountOption(m.Options, "lowerdir"),
+				UpperDir: mountOption(m.Options, "upperdir"),
+				WorkDir:  mountOption(m.Options, "workdir"),
+			}
 		}
-		si.Storage = append(si.Storage, device)
+		parts = append(parts, partition)
 	}
+	sortPartitions(parts)
+
+	return parts
 }
 
-func diskUsage(path string) (used uint64, err error) {
-	var stat unix.Statfs_t
-	if err = unix.Statfs(path, &stat); err != nil {
-		return
+// Parse size mount option (tmpfs reports it in KB, like size=6147400k), return bytes.
+func parseMountSize(value string) (uint64, bool) {
+	if value == "" {
+		return 0, false
 	}
-	used = stat.Bavail * uint64(stat.Bsize)
-	return
+
+	mult := uint64(1)
+	switch value[len(value)-1] {
+	case 'k', 'K':
+		mult = 1 << 10
+	case 'm', 'M':
+		mult = 1 << 20
+	case 'g', 'G':
+		mult = 1 << 30
+	}
+	if mult != 1 {
+		value = value[:len(value)-1]
+	}
+
+	size, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return size * mult, true
+}
+
+// Partitions of device-mapper devices, made by kpartx, are named after the device, like mpatha1, mpathap1 or
+// mpatha-part1.
+var dmPartSuffix = regexp.MustCompile(`^(p|-part)?[0-9]+$`)
+
+// Get the kernel name of the mounted device node, if it's the device itself or one of its partitions. Device names
+// are not matched by prefix, sda1 is not a partition of sd, nor is vg-root2 of vg-root.
+func (si *SysInfo) partitionOf(name, fullpath, devnode string) (string, bool) {
+	partName := strings.TrimPrefix(devnode, "/dev/")
+	if partName == devnode || strings.Contains(partName, "/") {
+		return "", false
+	}
+	if partName == name {
+		return partName, true
+	}
+	if _, err := si.stat(path.Join(fullpath, partName, "partition")); err == nil {
+		return partName, true
+	}
+
+	// Device-mapper partitions are devices of their own, stacked on top of the one they partition.
+	dmName := si.slurpFile(path.Join(fullpath, "dm", "name"))
+	partDMName := si.slurpFile(path.Join("/sys/block", partName, "dm", "name"))
+	if dmName == "" || !strings.HasPrefix(partDMName, dmName) || !dmPartSuffix.MatchString(partDMName[len(dmName):]) {
+		return "", false
+	}
+	if _, err := si.stat(path.Join("/sys/block", partName, "slaves", name)); err != nil {
+		return "", false
+	}
+
+	return partName, true
+}
+
+// Get the value of a key=value mount option.
+func mountOption(options, key string) string {
+	for _, opt := range strings.Split(options, ",") {
+		if sl := strings.SplitN(opt, "=", 2); len(sl) == 2 && sl[0] == key {
+			return sl[1]
+		}
+	}
+
+	return ""
+}
+
+// Mount options enabling disk quotas, as shown by ext4 & xfs (usrjquota= and grpjquota= name journaled quota files).
+var quotaOptions = map[string]bool{
+	"quota": true, "usrquota": true, "grpquota": true, "prjquota": true,
+	"uquota": true, "gquota": true, "pquota": true, "usrjquota": true, "grpjquota": true,
+}
+
+func hasQuota(options string) bool {
+	for _, opt := range strings.Split(options, ",") {
+		if quotaOptions[strings.SplitN(opt, "=", 2)[0]] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Space reserved for the superuser (free but not available), as percentage of the filesystem size.
+func reservedPercent(size, free, avail uint64) float64 {
+	if size == 0 || free < avail {
+		return 0
+	}
+
+	return float64(free-avail) / float64(size) * 100
 }
@@ -0,0 +1,98 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+//go:build darwin
+// +build darwin
+
+package sysinfo
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// Disk ioctls from <sys/disk.h>
+const (
+	dkiocGetBlockSize  = 0x40046418 // _IOR('d', 24, uint32_t)
+	dkiocGetBlockCount = 0x40086419 // _IOR('d', 25, uint64_t)
+)
+
+// Slices of the whole disk are named like disk1s1, or disk1s2s1 for snapshots.
+var reDiskSlice = regexp.MustCompile(`^(disk\d+)(s\d+)+$`)
+
+// Get device size in bytes, via disk ioctls.
+func getDiskSize(devpath string) uint64 {
+	fd, err := unix.Open(devpath, unix.O_RDONLY, 0)
+	if err != nil {
+		return 0
+	}
+	defer unix.Close(fd)
+
+	blockSize, err := unix.IoctlGetInt(fd, dkiocGetBlockSize)
+	if err != nil {
+		return 0
+	}
+	blockCount, err := unix.IoctlGetInt(fd, dkiocGetBlockCount)
+	if err != nil {
+		return 0
+	}
+
+	return uint64(blockSize) * uint64(blockCount)
+}
+
+func (si *SysInfo) getStorageInfo() {
+	kbSize := uint64(si.kbSize())
+
+	n, err := unix.Getfsstat(nil, unix.MNT_NOWAIT)
+	if err != nil || n == 0 {
+		return
+	}
+
+	buf := make([]unix.Statfs_t, n)
+	if n, err = unix.Getfsstat(buf, unix.MNT_NOWAIT); err != nil {
+		return
+	}
+
+	si.Storage = make([]StorageDevice, 0)
+	devices := make(map[string]int)
+	for _, stat := range buf[:n] {
+		from := unix.ByteSliceToString(stat.Mntfromname[:])
+		if !strings.HasPrefix(from, "/dev/disk") {
+			continue
+		}
+
+		partName := path.Base(from)
+		name := partName
+		if m := reDiskSlice.FindStringSubmatch(partName); m != nil {
+			name = m[1]
+		}
+
+		i, exist := devices[name]
+		if !exist {
+			si.Storage = append(si.Storage, StorageDevice{
+				Name: name,
+				Size: uint(getDiskSize("/dev/"+name) / kbSize / kbSize),
+			})
+			i = len(si.Storage) - 1
+			devices[name] = i
+		}
+
+		si.Storage[i].Partitions = append(si.Storage[i].Partitions, Partition{
+			Name:          partName,
+			MountPoint:    unix.ByteSliceToString(stat.Mntonname[:]),
+			Size:          uint(stat.Blocks * uint64(stat.Bsize) / kbSize / kbSize),
+			AvailableSize: uint(stat.Bavail * uint64(stat.Bsize) / 1024 / 1024),
+		})
+	}
+
+	// Mount table order isn't stable, list devices by name, the same as on other platforms.
+	sort.Slice(si.Storage, func(i, j int) bool { return si.Storage[i].Name < si.Storage[j].Name })
+	for i := range si.Storage {
+		sortPartitions(si.Storage[i].Partitions)
+	}
+}
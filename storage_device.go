@@ -0,0 +1,34 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+//go:build !linux
+// +build !linux
+
+package sysinfo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ParseMounts returns the mount table, supported on Linux only.
+func ParseMounts() ([]Mount, error) {
+	return nil, errors.New("sysinfo: mount table parsing not supported")
+}
+
+// GetStorageDevice gathers information about a single storage device, by its name (like ada0). Outside Linux it's
+// picked from all the collected storage devices.
+func GetStorageDevice(name string, cfg Config) (StorageDevice, error) {
+	si := SysInfo{Config: cfg}
+	si.getStorageInfo()
+
+	for _, device := range si.Storage {
+		if device.Name == name {
+			return device, nil
+		}
+	}
+
+	return StorageDevice{}, fmt.Errorf("storage device %s: %w", name, os.ErrNotExist)
+}
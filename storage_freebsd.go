@@ -0,0 +1,105 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+//go:build freebsd
+// +build freebsd
+
+package sysinfo
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// Map mounted devices to their mount points and usage, with getfsstat(2).
+func getMounts() map[string]unix.Statfs_t {
+	mounts := make(map[string]unix.Statfs_t)
+
+	n, err := unix.Getfsstat(nil, unix.MNT_NOWAIT)
+	if err != nil || n == 0 {
+		return mounts
+	}
+
+	buf := make([]unix.Statfs_t, n)
+	if n, err = unix.Getfsstat(buf, unix.MNT_NOWAIT); err != nil {
+		return mounts
+	}
+
+	for _, stat := range buf[:n] {
+		from := unix.ByteSliceToString(stat.Mntfromname[:])
+		if _, exist := mounts[from]; !exist {
+			mounts[from] = stat
+		}
+	}
+
+	return mounts
+}
+
+func (si *SysInfo) getStorageInfo() {
+	kbSize := uint64(si.kbSize())
+
+	// GEOM configuration, one provider per line, indented by depth:
+	// 0 DISK ada0 500107862016 512 hd 16 sc 63
+	// 1 PART ada0p1 524288 512 i 1 o 20480 ty freebsd-boot xs GPT xt 83bd6b9d-7f41-11dc-be0b-001560b84f0f
+	conftxt, err := unix.Sysctl("kern.geom.conftxt")
+	if err != nil {
+		return
+	}
+
+	mounts := getMounts()
+
+	si.Storage = make([]StorageDevice, 0)
+	var device *StorageDevice
+	for _, line := range strings.Split(conftxt, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+
+		mediaSize, _ := strconv.ParseUint(fields[3], 10, 64)
+
+		switch fields[1] {
+		case "DISK":
+			si.Storage = append(si.Storage, StorageDevice{
+				Name:   fields[2],
+				Driver: strings.TrimRight(fields[2], "0123456789"),
+				Size:   uint(mediaSize / kbSize / kbSize),
+			})
+			device = &si.Storage[len(si.Storage)-1]
+		case "PART":
+			if device == nil || fields[0] != "1" {
+				continue
+			}
+
+			// Key-value pairs follow the name, media size and sector size.
+			for i := 5; i+1 < len(fields); i += 2 {
+				if fields[i] == "xs" {
+					device.PartitionType = strings.ToLower(fields[i+1])
+				}
+			}
+
+			stat, ok := mounts["/dev/"+fields[2]]
+			if !ok {
+				continue
+			}
+
+			partition := Partition{
+				Name:       fields[2],
+				MountPoint: unix.ByteSliceToString(stat.Mntonname[:]),
+				Size:       uint(mediaSize / kbSize / kbSize),
+			}
+			if stat.Bavail > 0 {
+				partition.AvailableSize = uint(uint64(stat.Bavail) * stat.Bsize / 1024 / 1024)
+			}
+
+			device.Partitions = append(device.Partitions, partition)
+		}
+	}
+
+	for i := range si.Storage {
+		sortPartitions(si.Storage[i].Partitions)
+	}
+}
@@ -0,0 +1,11 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+//go:build !linux && !freebsd && !darwin && !windows
+// +build !linux,!freebsd,!darwin,!windows
+
+package sysinfo
+
+func (si *SysInfo) getStorageInfo() {
+}
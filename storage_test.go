@@ -0,0 +1,292 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package sysinfo
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// SCSI disk sdx, and the block device of it.
+const (
+	sdxDevice = "sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0"
+	sdx       = sdxDevice + "/block/sdx"
+)
+
+// Fake filesystem with the 1 GiB disk sdx in it, and the extra files & links. Extra /proc/partitions lines, for the
+// partitions, are appended to the disk's.
+func sdxFS(files map[string]string, links map[string]string) *testFS {
+	sdxFiles := map[string]string{
+		"proc/self/mountinfo": "",
+		sdx + "/size":         "2097152",
+	}
+	for name, data := range files {
+		sdxFiles[name] = data
+	}
+	sdxFiles["proc/partitions"] = "major minor  #blocks  name\n\n   8        0    1048576 sdx\n" + files["proc/partitions"]
+
+	sdxLinks := map[string]string{
+		"sys/block/sdx": "../devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sdx",
+	}
+	for name, target := range links {
+		sdxLinks[name] = target
+	}
+
+	return newTestFS(sdxFiles, sdxLinks)
+}
+
+func TestStorageFiltering(t *testing.T) {
+	si := SysInfo{Config: Config{FS: sdxFS(
+		map[string]string{
+			sdxDevice + "/model":                     "TEST DISK",
+			"sys/devices/virtual/block/loop7/size":   "1024",
+			"sys/devices/virtual/block/md9/size":     "4096",
+			"sys/devices/virtual/block/md9/md/level": "raid1",
+		},
+		map[string]string{
+			"sys/block/loop7": "../devices/virtual/block/loop7",
+			"sys/block/md9":   "../devices/virtual/block/md9",
+			sdx + "/device":   "../../../0:0:0:0",
+		},
+	)}}
+	si.getStorageInfo()
+
+	if len(si.Storage) != 2 {
+		t.Fatalf("got %d storage devices, want 2: %+v", len(si.Storage), si.Storage)
+	}
+
+	// Virtual devices are filtered out, md arrays are not.
+	if md := si.Storage[0]; md.Name != "md9" || md.RAID == nil || md.RAID.Level != "raid1" {
+		t.Errorf("got %+v, want md9 raid1 array", md)
+	}
+	if disk := si.Storage[1]; disk.Name != "sdx" || disk.Model != "TEST DISK" {
+		t.Errorf("got %+v, want sdx TEST DISK", disk)
+	}
+}
+
+func TestPartitionTypes(t *testing.T) {
+	si := SysInfo{Config: Config{FS: sdxFS(
+		map[string]string{
+			"proc/partitions":       "   8        1     524288 sdx1\n   8        2     524288 sdx2\n",
+			"proc/self/mountinfo":   "22 1 8:2 / / rw,relatime shared:1 - ext4 /dev/sdx2 rw\n",
+			sdx + "/sdx1/partition": "1",
+			sdx + "/sdx1/dev":       "8:1",
+			sdx + "/sdx2/partition": "2",
+			sdx + "/sdx2/dev":       "8:2",
+			"run/udev/data/b8:1":    "E:ID_PART_ENTRY_TYPE=c12a7328-f81f-11d2-ba4b-00a0c93ec93b\n",
+			"run/udev/data/b8:2":    "E:ID_PART_ENTRY_TYPE=0FC63DAF-8483-4772-8E79-3D69D8477DE4\n",
+		},
+		nil,
+	)}}
+	si.getStorageInfo()
+
+	if len(si.Storage) != 1 {
+		t.Fatalf("got %d storage devices, want 1: %+v", len(si.Storage), si.Storage)
+	}
+
+	// Unmounted ESP is listed too, without the mount point.
+	want := []Partition{
+		{Name: "sdx1", Size: 536, PartType: "ESP"},
+		{Name: "sdx2", MountPoint: "/", Size: 536, PartType: "linux-filesystem", Propagation: "shared"},
+	}
+	if got := si.Storage[0].Partitions; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestSymlinkedMountDevice(t *testing.T) {
+	// Links the way udev creates them, mounted the way systemd's fstab generator records them.
+	for _, source := range []string{
+		"/dev/sdx1",
+		"/dev/disk/by-uuid/1234-ABCD",
+		"/dev/disk/by-partuuid/0f1e2d3c-01",
+		"/dev/disk/by-label/USB",
+	} {
+		si := SysInfo{Config: Config{FS: sdxFS(
+			map[string]string{
+				"proc/partitions":       "   8        1    1048576 sdx1\n",
+				"proc/self/mountinfo":   "40 1 8:1 / /media/usb rw,relatime shared:1 - vfat " + source + " rw\n",
+				sdx + "/sdx1/partition": "1",
+				"dev/sdx1":              "",
+			},
+			map[string]string{
+				"dev/disk/by-uuid/1234-ABCD":       "../../sdx1",
+				"dev/disk/by-partuuid/0f1e2d3c-01": "../../sdx1",
+				"dev/disk/by-label/USB":            "/dev/sdx1",
+			},
+		)}}
+		si.getStorageInfo()
+
+		if len(si.Storage) != 1 {
+			t.Fatalf("%s: got %d storage devices, want 1: %+v", source, len(si.Storage), si.Storage)
+		}
+		want := []Partition{{Name: "sdx1", MountPoint: "/media/usb", Size: 1073, Propagation: "shared"}}
+		if got := si.Storage[0].Partitions; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %+v, want %+v", source, got, want)
+		}
+	}
+}
+
+func TestDeviceMapperMounts(t *testing.T) {
+	// Logical volumes, one's name the prefix of the other's.
+	fsys := newTestFS(
+		map[string]string{
+			"proc/partitions": "major minor  #blocks  name\n\n 253        0    1048576 dm-0\n" +
+				" 253        1    2097152 dm-1\n",
+			"proc/self/mountinfo": "22 1 253:0 / / rw,relatime shared:1 - ext4 /dev/mapper/vg-root rw\n" +
+				"23 22 253:1 / /snap rw,relatime shared:2 - ext4 /dev/mapper/vg-root2 rw\n",
+			"sys/devices/virtual/block/dm-0/size":    "2097152",
+			"sys/devices/virtual/block/dm-0/dm/name": "vg-root",
+			"sys/devices/virtual/block/dm-1/size":    "4194304",
+			"sys/devices/virtual/block/dm-1/dm/name": "vg-root2",
+		},
+		map[string]string{
+			"sys/block/dm-0": "../devices/virtual/block/dm-0",
+			"sys/block/dm-1": "../devices/virtual/block/dm-1",
+		},
+	)
+
+	for name, want := range map[string][]Partition{
+		"dm-0": {{Name: "dm-0", MountPoint: "/", Size: 1073, Propagation: "shared"}},
+		"dm-1": {{Name: "dm-1", MountPoint: "/snap", Size: 2147, Propagation: "shared"}},
+	} {
+		device, err := GetStorageDevice(name, Config{FS: fsys})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(device.Partitions, want) {
+			t.Errorf("%s: got %+v, want %+v", name, device.Partitions, want)
+		}
+	}
+}
+
+func TestMultipathPartitions(t *testing.T) {
+	const dm = "sys/devices/virtual/block/"
+
+	// Partitions made by kpartx, of multipath devices, one's name the prefix of the other's.
+	fsys := newTestFS(
+		map[string]string{
+			"proc/partitions": "major minor  #blocks  name\n\n 253        0    1048576 dm-0\n" +
+				" 253        1    1048576 dm-1\n 253        2     524288 dm-2\n 253        3     524288 dm-3\n",
+			"proc/self/mountinfo": "22 1 253:2 / /data rw,relatime shared:1 - xfs /dev/mapper/mpatha-part1 rw\n" +
+				"23 1 253:3 / /data2 rw,relatime shared:2 - xfs /dev/mapper/mpathab1 rw\n",
+			dm + "dm-0/size":       "2097152",
+			dm + "dm-0/dm/name":    "mpatha",
+			dm + "dm-0/dm/uuid":    "mpath-3600a098038303053",
+			dm + "dm-1/size":       "2097152",
+			dm + "dm-1/dm/name":    "mpathab",
+			dm + "dm-1/dm/uuid":    "mpath-3600a098038303054",
+			dm + "dm-2/dm/name":    "mpatha-part1",
+			dm + "dm-2/dm/uuid":    "part1-mpath-3600a098038303053",
+			dm + "dm-2/dev":        "253:2",
+			dm + "dm-3/dm/name":    "mpathab1",
+			dm + "dm-3/dm/uuid":    "part1-mpath-3600a098038303054",
+			"run/udev/data/b253:2": "E:ID_PART_ENTRY_TYPE=0fc63daf-8483-4772-8e79-3d69d8477de4\n",
+		},
+		map[string]string{
+			"sys/block/dm-0":        "../devices/virtual/block/dm-0",
+			"sys/block/dm-1":        "../devices/virtual/block/dm-1",
+			"sys/block/dm-2":        "../devices/virtual/block/dm-2",
+			"sys/block/dm-3":        "../devices/virtual/block/dm-3",
+			dm + "dm-2/slaves/dm-0": "../../dm-0",
+			dm + "dm-3/slaves/dm-1": "../../dm-1",
+		},
+	)
+
+	for name, want := range map[string][]Partition{
+		"dm-0": {{Name: "dm-2", MountPoint: "/data", Size: 536, PartType: "linux-filesystem", Propagation: "shared"}},
+		"dm-1": {{Name: "dm-3", MountPoint: "/data2", Size: 536, Propagation: "shared"}},
+	} {
+		device, err := GetStorageDevice(name, Config{FS: fsys})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(device.Partitions, want) {
+			t.Errorf("%s: got %+v, want %+v", name, device.Partitions, want)
+		}
+	}
+}
+
+func TestParseMounts(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		files map[string]string
+		want  []Mount
+	}{
+		{
+			"mountinfo",
+			map[string]string{
+				"proc/self/mountinfo": "22 1 253:1 / / rw,relatime shared:1 - ext4 /dev/vda1 rw\n" +
+					"36 22 253:2 /data /mnt/my\\040data rw master:2 - xfs /dev/vdb rw,noquota\n",
+			},
+			[]Mount{
+				{ID: 22, ParentID: 1, Root: "/", Device: "/dev/vda1", MountPoint: "/", FSType: "ext4", Options: "rw,relatime", Propagation: "shared"},
+				{ID: 36, ParentID: 22, Root: "/data", Device: "/dev/vdb", MountPoint: "/mnt/my data", FSType: "xfs", Options: "rw,noquota", Propagation: "slave"},
+			},
+		},
+		{
+			"mounts",
+			map[string]string{
+				"proc/mounts": "/dev/vda1 / ext4 rw,relatime 0 1\n",
+			},
+			[]Mount{
+				{Root: "/", Device: "/dev/vda1", MountPoint: "/", FSType: "ext4", Options: "rw,relatime", Pass: 1},
+			},
+		},
+	} {
+		si := SysInfo{Config: Config{FS: newTestFS(tc.files, nil)}}
+		got, err := si.parseMounts()
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
+
+// Fake filesystem with statfs taking a while, like it does on the real ones.
+type slowStatfsFS struct {
+	*testFS
+}
+
+func (t slowStatfsFS) Statfs(name string) (FSUsage, error) {
+	time.Sleep(time.Millisecond)
+	return FSUsage{Size: 1 << 30, Free: 1 << 29, Available: 1 << 28}, nil
+}
+
+func BenchmarkStorageManyMounts(b *testing.B) {
+	var mountinfo strings.Builder
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&mountinfo, "%d 1 8:1 /%d /mnt/%d rw - ext4 /dev/sdx1 rw\n", 100+i, i, i)
+	}
+	fsys := slowStatfsFS{sdxFS(
+		map[string]string{
+			"proc/partitions":       "   8        1    1048576 sdx1\n",
+			"proc/self/mountinfo":   mountinfo.String(),
+			sdx + "/sdx1/partition": "1",
+		},
+		nil,
+	)}
+
+	for _, concurrency := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				si := SysInfo{Config: Config{FS: fsys, StatfsConcurrency: concurrency}}
+				si.getStorageInfo()
+				if n := len(si.Storage[0].Partitions); n != 200 {
+					b.Fatalf("got %d partitions, want 200", n)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+import (
+	"sort"
+	"time"
+)
+
+// StorageDevice information.
+type StorageDevice struct {
+	Name                string            `json:"name,omitempty" msgpack:"name,omitempty"`
+	Driver              string            `json:"driver,omitempty" msgpack:"drv,omitempty"`
+	Vendor              string            `json:"vendor,omitempty" msgpack:"vnd,omitempty"`
+	Model               string            `json:"model,omitempty" msgpack:"model,omitempty"`
+	Serial              string            `json:"serial,omitempty" msgpack:"sn,omitempty"`
+	Size                uint              `json:"size,omitempty" msgpack:"size,omitempty"`        // device size in MB
+	ExactBytes          uint64            `json:"exactBytes,omitempty" msgpack:"bytes,omitempty"` // device size in bytes, unrounded
+	Partitions          []Partition       `json:"partitions,omitempty" msgpack:"parts,omitempty"` // sorted by name, then mount point
+	PartitionType       string            `json:"partitionType,omitempty" msgpack:"pt,omitempty"`
+	SysPath             string            `json:"sysPath,omitempty" msgpack:"sys,omitempty"` // device path in sysfs
+	ByID                string            `json:"byId,omitempty" msgpack:"id,omitempty"`     // persistent name in /dev/disk/by-id
+	Multipath           *MultipathInfo    `json:"multipath,omitempty" msgpack:"mp,omitempty"`
+	PCIeGen             uint              `json:"pcieGen,omitempty" msgpack:"pgen,omitempty"`   // negotiated PCIe generation, NVMe only
+	PCIeWidth           uint              `json:"pcieWidth,omitempty" msgpack:"pwid,omitempty"` // negotiated PCIe lanes, NVMe only
+	SMART               map[string]uint64 `json:"smart,omitempty" msgpack:"smart,omitempty"`    // SMART attributes, ATA only
+	PowerOnHours        uint              `json:"powerOnHours,omitempty" msgpack:"poh,omitempty"`
+	WearLevelPercent    uint              `json:"wearLevelPercent,omitempty" msgpack:"wear,omitempty"` // SSD endurance used
+	NUMANode            *int              `json:"numaNode,omitempty" msgpack:"numa,omitempty"`         // NUMA node the device is attached to
+	Bcache              *BcacheInfo       `json:"bcache,omitempty" msgpack:"bc,omitempty"`
+	WriteCache          string            `json:"writeCache,omitempty" msgpack:"wc,omitempty"`             // write back or write through
+	QueueDepth          uint              `json:"queueDepth,omitempty" msgpack:"qd,omitempty"`             // SCSI device queue depth
+	NrRequests          uint              `json:"nrRequests,omitempty" msgpack:"nr,omitempty"`             // block layer request queue size
+	OpticalMediaPresent bool              `json:"opticalMediaPresent,omitempty" msgpack:"media,omitempty"` // CD/DVD drives only
+	Firmware            string            `json:"firmware,omitempty" msgpack:"fw,omitempty"`               // firmware revision
+	Zoned               string            `json:"zoned,omitempty" msgpack:"zone,omitempty"`                // zoned model: none, host-aware or host-managed
+	Children            []StorageDevice   `json:"children,omitempty" msgpack:"chld,omitempty"`             // devices stacked on top, with Config.BuildTree
+	SelfEncrypting      bool              `json:"selfEncrypting,omitempty" msgpack:"sed,omitempty"`        // TCG security protocols supported, with Config.EnableSMART
+	Scheduler           string            `json:"scheduler,omitempty" msgpack:"sched,omitempty"`           // active I/O scheduler
+	SchedulerParams     map[string]string `json:"schedulerParams,omitempty" msgpack:"schedp,omitempty"`    // tunables of the I/O scheduler
+	CriticalWarnings    []string          `json:"criticalWarnings,omitempty" msgpack:"cw,omitempty"`       // NVMe critical warnings, with Config.EnableSMART, none when healthy
+	RAID                *RAIDInfo         `json:"raid,omitempty" msgpack:"raid,omitempty"`                 // md software RAID arrays only
+	MinimumIOSize       uint              `json:"minimumIOSize,omitempty" msgpack:"minio,omitempty"`       // preferred minimum I/O size in bytes
+	OptimalIOSize       uint              `json:"optimalIOSize,omitempty" msgpack:"optio,omitempty"`       // optimal I/O size in bytes, like RAID stripe width
+	LinkSpeed           string            `json:"linkSpeed,omitempty" msgpack:"link,omitempty"`            // negotiated SATA link speed, like 6.0 Gbps, ATA only
+	MultiQueue          bool              `json:"multiQueue,omitempty" msgpack:"mq,omitempty"`             // blk-mq block layer
+	HardwareQueues      uint              `json:"hardwareQueues,omitempty" msgpack:"hwq,omitempty"`        // blk-mq hardware dispatch queues
+}
+
+// BcacheInfo describes the device's role in bcache, and the devices it's paired with.
+type BcacheInfo struct {
+	Role    string   `json:"role,omitempty" msgpack:"role,omitempty"`    // backing, cache, or bcache for the bcacheN device itself
+	Device  string   `json:"device,omitempty" msgpack:"dev,omitempty"`   // bcacheN device, on backing device
+	Backing []string `json:"backing,omitempty" msgpack:"back,omitempty"` // backing devices, on cache and bcacheN device
+	Cache   []string `json:"cache,omitempty" msgpack:"cache,omitempty"`  // cache devices, on backing and bcacheN device
+}
+
+// RAIDInfo describes an md software RAID array, and the resync or rebuild running on it.
+type RAIDInfo struct {
+	Level       string   `json:"level,omitempty" msgpack:"lvl,omitempty"`         // like raid1, raid5
+	State       string   `json:"state,omitempty" msgpack:"st,omitempty"`          // array state, like clean, active
+	Members     []string `json:"members,omitempty" msgpack:"mem,omitempty"`       // member devices, like sda1
+	SyncAction  string   `json:"syncAction,omitempty" msgpack:"sync,omitempty"`   // idle, resync, recover, check, repair, reshape
+	SyncPercent float64  `json:"syncPercent,omitempty" msgpack:"syncp,omitempty"` // progress of the running sync action
+	SyncSpeed   uint     `json:"syncSpeed,omitempty" msgpack:"syncs,omitempty"`   // speed of the running sync action in KB/s
+	Degraded    bool     `json:"degraded,omitempty" msgpack:"deg,omitempty"`      // array is missing some of its members
+	Failed      []string `json:"failed,omitempty" msgpack:"fail,omitempty"`       // faulty member devices
+}
+
+// MultipathInfo describes a device-mapper multipath device.
+type MultipathInfo struct {
+	Name  string   `json:"name,omitempty" msgpack:"name,omitempty"` // friendly name, like mpatha
+	WWID  string   `json:"wwid,omitempty" msgpack:"wwid,omitempty"`
+	Paths []string `json:"paths,omitempty" msgpack:"paths,omitempty"` // underlying path devices
+}
+
+// Partition information, one entry per mount, so the same partition is listed as many times as it's mounted.
+// Partitions that aren't mounted are listed once, without the mount point.
+type Partition struct {
+	Name            string       `json:"name,omitempty" msgpack:"name,omitempty"`
+	MountPoint      string       `json:"mountPoint,omitempty" msgpack:"mp,omitempty"`
+	Size            uint         `json:"size,omitempty" msgpack:"size,omitempty"`            // partition size in MB
+	AvailableSize   uint         `json:"availableSize,omitempty" msgpack:"avail,omitempty"`  // available space in MB
+	UsedSize        uint         `json:"usedSize,omitempty" msgpack:"used,omitempty"`        // used space in MB, for tmpfs & ramfs mounts
+	ReservedPercent float64      `json:"reservedPercent,omitempty" msgpack:"resv,omitempty"` // space reserved for the superuser
+	Propagation     string       `json:"propagation,omitempty" msgpack:"prop,omitempty"`     // mount propagation type (shared, slave, private, unbindable)
+	BindSource      string       `json:"bindSource,omitempty" msgpack:"bind,omitempty"`      // path within the partition, for bind mounts
+	Overlay         *OverlayInfo `json:"overlay,omitempty" msgpack:"ovl,omitempty"`
+	LastMount       *time.Time   `json:"lastMount,omitempty" msgpack:"lmnt,omitempty"`  // ext filesystems only, with Config.ReadSuperblocks
+	NeedsCheck      bool         `json:"needsCheck,omitempty" msgpack:"fsck,omitempty"` // ext filesystems only, with Config.ReadSuperblocks
+	QuotaEnabled    bool         `json:"quotaEnabled,omitempty" msgpack:"quota,omitempty"`
+	FSFeatures      []string     `json:"fsFeatures,omitempty" msgpack:"fsf,omitempty"` // ext filesystems only, with Config.ReadSuperblocks
+	PartType        string       `json:"partType,omitempty" msgpack:"ptype,omitempty"` // role by partition type, like ESP or linux-swap
+}
+
+// Mount describes a single entry of the mount table.
+type Mount struct {
+	ID          int    `json:"id,omitempty" msgpack:"id,omitempty"`        // mount ID, from /proc/self/mountinfo only
+	ParentID    int    `json:"parentId,omitempty" msgpack:"pid,omitempty"` // parent mount ID, from /proc/self/mountinfo only
+	Root        string `json:"root,omitempty" msgpack:"root,omitempty"`    // path within the filesystem mounted, other than / for bind mounts
+	Device      string `json:"device,omitempty" msgpack:"dev,omitempty"`
+	MountPoint  string `json:"mountPoint,omitempty" msgpack:"mp,omitempty"`
+	FSType      string `json:"fsType,omitempty" msgpack:"fs,omitempty"`
+	Options     string `json:"options,omitempty" msgpack:"opt,omitempty"`      // mount & superblock options, comma separated, each once
+	Propagation string `json:"propagation,omitempty" msgpack:"prop,omitempty"` // from /proc/self/mountinfo only
+	Dump        int    `json:"dump,omitempty" msgpack:"dump,omitempty"`        // from /proc/mounts only, always 0 on Linux
+	Pass        int    `json:"pass,omitempty" msgpack:"pass,omitempty"`        // from /proc/mounts only, always 0 on Linux
+}
+
+// OverlayInfo describes the layers of an overlay filesystem.
+type OverlayInfo struct {
+	LowerDir string `json:"lowerDir,omitempty" msgpack:"lower,omitempty"` // colon separated list of lower layers
+	UpperDir string `json:"upperDir,omitempty" msgpack:"upper,omitempty"`
+	WorkDir  string `json:"workDir,omitempty" msgpack:"work,omitempty"`
+}
+
+// Order partitions by name, then by mount point.
+func sortPartitions(parts []Partition) {
+	sort.Slice(parts, func(i, j int) bool {
+		if parts[i].Name != parts[j].Name {
+			return parts[i].Name < parts[j].Name
+		}
+		return parts[i].MountPoint < parts[j].MountPoint
+	})
+}
+
+// Size unit used for storage sizes, as configured. Anything but 1000 or 1024 would only corrupt the sizes, so
+// unset and invalid values fall back to the default.
+func (si *SysInfo) kbSize() int {
+	return validKBSize(si.Config.KBSize)
+}
+
+func validKBSize(kbSize int) int {
+	switch kbSize {
+	case 1000, 1024:
+		return kbSize
+	}
+
+	return 1000
+}
@@ -0,0 +1,24 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+import "testing"
+
+func TestValidKBSize(t *testing.T) {
+	for _, tc := range []struct {
+		in, want int
+	}{
+		{0, 1000},
+		{1000, 1000},
+		{1024, 1024},
+		{1, 1000},
+		{-1024, 1000},
+		{512, 1000},
+	} {
+		if got := validKBSize(tc.in); got != tc.want {
+			t.Errorf("validKBSize(%d) = %d, want %d", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Control codes, from winioctl.h
+const (
+	ioctlStorageGetDeviceNumber = 0x2d1080
+	ioctlStorageQueryProperty   = 0x2d1400
+	ioctlDiskGetLengthInfo      = 0x7405c
+	ioctlDiskGetDriveLayoutEx   = 0x70050
+)
+
+// Physical drives are numbered, but not necessarily contiguously, when disks are removed.
+const maxPhysicalDrives = 32
+
+// STORAGE_BUS_TYPE, from winioctl.h
+var storageBusTypes = map[uint32]string{
+	1: "scsi", 2: "atapi", 3: "ata", 4: "1394", 5: "ssa", 6: "fibre", 7: "usb", 8: "raid", 9: "iscsi", 10: "sas",
+	11: "sata", 12: "sd", 13: "mmc", 14: "virtual", 15: "filebackedvirtual", 16: "spaces", 17: "nvme", 18: "scm",
+	19: "ufs",
+}
+
+// PARTITION_STYLE, from winioctl.h
+var partitionStyles = map[uint32]string{0: "mbr", 1: "gpt"}
+
+func openDevice(name string) (windows.Handle, error) {
+	p, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return windows.InvalidHandle, err
+	}
+
+	return windows.CreateFile(p, windows.GENERIC_READ, windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE, nil,
+		windows.OPEN_EXISTING, 0, 0)
+}
+
+func deviceIoControl(h windows.Handle, code uint32, in, out []byte) ([]byte, error) {
+	var inPtr *byte
+	if len(in) > 0 {
+		inPtr = &in[0]
+	}
+
+	var n uint32
+	if err := windows.DeviceIoControl(h, code, inPtr, uint32(len(in)), &out[0], uint32(len(out)), &n, nil); err != nil {
+		return nil, err
+	}
+
+	return out[:n], nil
+}
+
+// Null terminated string at the offset, in STORAGE_DEVICE_DESCRIPTOR.
+func descriptorString(data []byte, offset uint32) string {
+	if offset == 0 || int(offset) >= len(data) {
+		return ""
+	}
+
+	s := data[offset:]
+	if i := strings.IndexByte(string(s), 0); i >= 0 {
+		s = s[:i]
+	}
+
+	return strings.TrimSpace(string(s))
+}
+
+func getDiskInfo(h windows.Handle, device *StorageDevice, kbSize uint64) {
+	le := binary.LittleEndian
+
+	// STORAGE_PROPERTY_QUERY, StorageDeviceProperty & PropertyStandardQuery
+	query := make([]byte, 12)
+	if data, err := deviceIoControl(h, ioctlStorageQueryProperty, query, make([]byte, 1024)); err == nil && len(data) >= 32 {
+		// STORAGE_DEVICE_DESCRIPTOR
+		device.Vendor = descriptorString(data, le.Uint32(data[12:]))
+		device.Model = descriptorString(data, le.Uint32(data[16:]))
+		device.Firmware = descriptorString(data, le.Uint32(data[20:]))
+		device.Serial = descriptorString(data, le.Uint32(data[24:]))
+		device.Driver = storageBusTypes[le.Uint32(data[28:])]
+	}
+
+	// GET_LENGTH_INFORMATION
+	if data, err := deviceIoControl(h, ioctlDiskGetLengthInfo, nil, make([]byte, 8)); err == nil && len(data) == 8 {
+		device.Size = uint(le.Uint64(data) / kbSize / kbSize)
+	}
+
+	// DRIVE_LAYOUT_INFORMATION_EX, starting with the partition style, room for 128 GPT partitions
+	if data, err := deviceIoControl(h, ioctlDiskGetDriveLayoutEx, nil, make([]byte, 48+128*144)); err == nil && len(data) >= 4 {
+		device.PartitionType = partitionStyles[le.Uint32(data)]
+	}
+}
+
+// Physical drive number the volume lives on, volumes spanning more disks are not supported.
+func getVolumeDiskNumber(letter string) (uint32, bool) {
+	h, err := openDevice(`\\.\` + letter)
+	if err != nil {
+		return 0, false
+	}
+	defer windows.CloseHandle(h)
+
+	// STORAGE_DEVICE_NUMBER
+	var number struct {
+		DeviceType      uint32
+		DeviceNumber    uint32
+		PartitionNumber uint32
+	}
+	out := (*[unsafe.Sizeof(number)]byte)(unsafe.Pointer(&number))[:]
+	if _, err := deviceIoControl(h, ioctlStorageGetDeviceNumber, nil, out); err != nil {
+		return 0, false
+	}
+
+	return number.DeviceNumber, true
+}
+
+func (si *SysInfo) getStorageInfo() {
+	kbSize := uint64(si.kbSize())
+
+	// Physical drive access requires administrator privileges.
+	si.Storage = make([]StorageDevice, 0)
+	disks := make(map[uint32]int)
+	for n := uint32(0); n < maxPhysicalDrives; n++ {
+		name := fmt.Sprintf("PhysicalDrive%d", n)
+		h, err := openDevice(`\\.\` + name)
+		if err != nil {
+			continue
+		}
+
+		device := StorageDevice{Name: name}
+		getDiskInfo(h, &device, kbSize)
+		windows.CloseHandle(h)
+
+		si.Storage = append(si.Storage, device)
+		disks[n] = len(si.Storage) - 1
+	}
+
+	// Drive letters are the mount points of the partitions.
+	drives, err := windows.GetLogicalDrives()
+	if err != nil {
+		return
+	}
+	for i := 0; i < 26; i++ {
+		if drives&(1<<uint(i)) == 0 {
+			continue
+		}
+
+		letter := string(rune('A'+i)) + ":"
+		root, _ := windows.UTF16PtrFromString(letter + `\`)
+		if windows.GetDriveType(root) != windows.DRIVE_FIXED {
+			continue
+		}
+
+		n, ok := getVolumeDiskNumber(letter)
+		if !ok {
+			continue
+		}
+		index, ok := disks[n]
+		if !ok {
+			continue
+		}
+
+		partition := Partition{
+			Name:       letter,
+			MountPoint: letter + `\`,
+		}
+		var avail, total, free uint64
+		if err := windows.GetDiskFreeSpaceEx(root, &avail, &total, &free); err == nil {
+			partition.Size = uint(total / kbSize / kbSize)
+			partition.AvailableSize = uint(avail / 1024 / 1024)
+		}
+
+		si.Storage[index].Partitions = append(si.Storage[index].Partitions, partition)
+	}
+}
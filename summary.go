@@ -0,0 +1,42 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+import "strings"
+
+// Summary of the system at a glance, derived from the other sections.
+type Summary struct {
+	TotalStorageBytes uint64 `json:"totalstoragebytes,omitempty" msgpack:"stor,omitempty"` // size of all storage devices in bytes
+	TotalMemoryBytes  uint64 `json:"totalmemorybytes,omitempty" msgpack:"mem,omitempty"`   // installed RAM size in bytes
+	CPUCount          uint   `json:"cpucount,omitempty" msgpack:"cpus,omitempty"`          // number of logical CPUs
+}
+
+// Aggregate sections gathered, after all the collectors ran. Virtual devices are left out, stacked ones (md arrays,
+// bcache devices, and the ones nested with Config.BuildTree) would count the same space twice, and loop devices hold
+// files on the other disks. Multipath devices are the exception, they are counted instead of their paths.
+func (si *SysInfo) getSummary() {
+	memory := si.Memory.Installed
+	if memory == 0 {
+		memory = si.Memory.Size
+	}
+	si.Summary = Summary{
+		TotalMemoryBytes: uint64(memory) << 20,
+		CPUCount:         si.CPU.Threads,
+	}
+
+	kbSize := uint64(si.kbSize())
+	for _, device := range si.Storage {
+		virtual := strings.Contains(device.SysPath, "/devices/virtual/")
+		if (virtual && device.Multipath == nil) || strings.HasPrefix(device.Name, "loop") {
+			continue
+		}
+		if device.ExactBytes > 0 {
+			si.Summary.TotalStorageBytes += device.ExactBytes
+		} else {
+			// Platforms that don't tell the exact size.
+			si.Summary.TotalStorageBytes += uint64(device.Size) * kbSize * kbSize
+		}
+	}
+}
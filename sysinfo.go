@@ -5,50 +5,138 @@
 // Package sysinfo is a Go library providing Linux OS / kernel / hardware system information.
 package sysinfo
 
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"time"
+)
+
+// Config alters the behavior of the information gathering.
 type Config struct {
-	KBSize int
+	KBSize            int      // size unit for storage sizes, 1000 (default) or 1024, other values are ignored
+	IncludeTmpfs      bool     // report tmpfs & ramfs mounts under synthetic storage devices
+	EnableSMART       bool     // read SMART data from disks, requires superuser privileges
+	IncludeOptical    bool     // report CD/DVD drives, too
+	Sysctls           []string // sysctl keys to report (like vm.swappiness), nil for the default set
+	BuildTree         bool     // nest stacked devices (device-mapper, md) under the devices they are built upon
+	Logger            Logger   // traces the files read & read errors, for debugging, nil disables tracing
+	Unprivileged      bool     // skip ethtool & wireless ioctls, report only what sysfs & procfs tell
+	CacheStatic       bool     // gather hardware info that never changes (DMI, CPU, memory modules, disk serials) only once
+	FS                FS       // system files are read from, nil for the real ones, device nodes are always accessed directly
+	StatfsConcurrency int      // filesystems queried for usage at the same time, 4 when unset
+	ReadSuperblocks   bool     // read ext filesystem superblocks from the devices, requires superuser privileges
+}
+
+// Logger receives debug trace lines, *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
 }
 
 // SysInfo struct encapsulates all other information structs.
 type SysInfo struct {
-	Meta    Meta            `json:"sysinfo"`
-	Node    Node            `json:"node"`
-	OS      OS              `json:"os"`
-	Kernel  Kernel          `json:"kernel"`
-	Product Product         `json:"product"`
-	Board   Board           `json:"board"`
-	Chassis Chassis         `json:"chassis"`
-	BIOS    BIOS            `json:"bios"`
-	CPU     CPU             `json:"cpu"`
-	Memory  Memory          `json:"memory"`
-	Storage []StorageDevice `json:"storage,omitempty"`
-	Network []NetworkDevice `json:"network,omitempty"`
-	Config  Config          `json:"-"`
-}
-
-// GetSysInfo gathers all available system information.
+	Meta         Meta            `json:"sysinfo" msgpack:"si"`
+	Node         Node            `json:"node" msgpack:"node"`
+	OS           OS              `json:"os" msgpack:"os"`
+	Kernel       Kernel          `json:"kernel" msgpack:"kern"`
+	Product      Product         `json:"product" msgpack:"prod"`
+	Board        Board           `json:"board" msgpack:"board"`
+	Chassis      Chassis         `json:"chassis" msgpack:"chas"`
+	BIOS         BIOS            `json:"bios" msgpack:"bios"`
+	CPU          CPU             `json:"cpu" msgpack:"cpu"`
+	Memory       Memory          `json:"memory" msgpack:"mem"`
+	Storage      []StorageDevice `json:"storage,omitempty" msgpack:"stor,omitempty"`
+	Network      []NetworkDevice `json:"network,omitempty" msgpack:"net,omitempty"`
+	ThermalZones []ThermalZone   `json:"thermal,omitempty" msgpack:"thm,omitempty"`
+	CStates      []CPUIdle       `json:"cstates,omitempty" msgpack:"cst,omitempty"`
+	Routing      Routing         `json:"routing" msgpack:"rt"`
+	Summary      Summary         `json:"summary" msgpack:"sum"`
+	Config       Config          `json:"-" msgpack:"-"`
+
+	cache *staticCache
+}
+
+// GetSysInfo gathers all available system information, running the built-in and all the registered collectors.
+// Errors returned by collectors are ignored, like everything else the information is gathered on the best effort
+// basis. Summary is computed last, from what all the collectors gathered.
 func (si *SysInfo) GetSysInfo() {
-	// Meta info
-	si.getMetaInfo()
+	for _, c := range registeredCollectors() {
+		_ = c.Collect(si)
+	}
+
+	si.getSummary()
+}
+
+// Load reads SysInfo back from the JSON snapshot, the way it was gathered and marshaled elsewhere. Unknown fields,
+// written by newer versions, are ignored, and the missing ones, not known to older versions, are left empty.
+// Meta.SchemaVersion tells which fields to expect.
+func Load(r io.Reader) (SysInfo, error) {
+	var si SysInfo
+	if err := json.NewDecoder(r).Decode(&si); err != nil {
+		return SysInfo{}, err
+	}
+
+	return si, nil
+}
 
-	// DMI info
-	si.getProductInfo()
-	si.getBoardInfo()
-	si.getChassisInfo()
-	si.getBIOSInfo()
+// Equal reports whether si and other describe the same system. Measurements that change all the time, like the
+// collection timestamp, free space or temperatures, the configuration and the cache are ignored. Empty lists and maps
+// equal missing ones, and timestamps are compared regardless of their location, so a snapshot read back with Load
+// equals the one it was written from.
+func (si SysInfo) Equal(other SysInfo) bool {
+	si.Config, other.Config = Config{}, Config{}
+	si.cache, other.cache = nil, nil
 
-	// SMBIOS info
-	si.getMemoryInfo()
+	a, b := deepCopy(reflect.ValueOf(si)), deepCopy(reflect.ValueOf(other))
+	normalize(a)
+	normalize(b)
 
-	// Node info
-	si.getNodeInfo() // depends on BIOS info
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// Fields Equal ignores, by their struct type.
+var volatileFields = map[reflect.Type][]string{
+	reflect.TypeOf(Meta{}):        {"Timestamp"},
+	reflect.TypeOf(Kernel{}):      {"Processes", "Threads", "Files", "Inodes", "InodesFree", "EntropyAvailable"},
+	reflect.TypeOf(CPU{}):         {"ClockMHz"},
+	reflect.TypeOf(NUMANode{}):    {"MemFree", "MemUsed"},
+	reflect.TypeOf(Partition{}):   {"AvailableSize", "UsedSize", "ReservedPercent"},
+	reflect.TypeOf(RAIDInfo{}):    {"SyncPercent", "SyncSpeed"},
+	reflect.TypeOf(ThermalZone{}): {"Temperature"},
+	reflect.TypeOf(CState{}):      {"Usage", "Time"},
+}
 
-	// Hardware info
-	si.getCPUInfo() // depends on Node info
-	si.getStorageInfo()
-	si.getNetworkInfo()
+// Bring the value to the form JSON round trip leaves it in, and clear the volatile fields, in place.
+func normalize(v reflect.Value) {
+	if v.Type() == timeType {
+		v.Set(reflect.ValueOf(v.Interface().(time.Time).UTC()))
+		return
+	}
 
-	// Software info
-	si.getOSInfo()
-	si.getKernelInfo()
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			normalize(v.Elem())
+		}
+	case reflect.Slice, reflect.Map:
+		if v.Len() == 0 {
+			v.Set(reflect.Zero(v.Type()))
+			return
+		}
+		if v.Kind() == reflect.Slice {
+			for i := 0; i < v.Len(); i++ {
+				normalize(v.Index(i))
+			}
+		}
+	case reflect.Struct:
+		for _, name := range volatileFields[v.Type()] {
+			f := v.FieldByName(name)
+			f.Set(reflect.Zero(f.Type()))
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				normalize(v.Field(i))
+			}
+		}
+	}
 }
@@ -0,0 +1,46 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+import (
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ThermalZone information.
+type ThermalZone struct {
+	Name        string  `json:"name,omitempty" msgpack:"name,omitempty"`
+	Type        string  `json:"type,omitempty" msgpack:"type,omitempty"`
+	Temperature float64 `json:"temperature,omitempty" msgpack:"temp,omitempty"` // temperature in °C
+}
+
+func (si *SysInfo) getThermalInfo() {
+	sysClassThermal := "/sys/class/thermal"
+	zones, err := si.readDir(sysClassThermal)
+	if err != nil {
+		return
+	}
+
+	si.ThermalZones = make([]ThermalZone, 0)
+	for _, link := range zones {
+		if !strings.HasPrefix(link.Name(), "thermal_zone") {
+			continue
+		}
+
+		fullpath := path.Join(sysClassThermal, link.Name())
+		zone := ThermalZone{
+			Name: link.Name(),
+			Type: si.slurpFile(path.Join(fullpath, "type")),
+		}
+
+		// Reported in millidegrees Celsius.
+		if temp, err := strconv.ParseInt(si.slurpFile(path.Join(fullpath, "temp")), 10, 64); err == nil {
+			zone.Temperature = float64(temp) / 1000
+		}
+
+		si.ThermalZones = append(si.ThermalZones, zone)
+	}
+}
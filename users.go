@@ -0,0 +1,100 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+import (
+	"bufio"
+	"bytes"
+	"path"
+	"sort"
+	"strings"
+)
+
+// struct utmp from /usr/include/bits/utmp.h
+const (
+	utmpSize        = 384
+	utmpUserProcess = 7
+	utmpTypeOffset  = 0
+	utmpUserOffset  = 44
+	utmpUserSize    = 32
+)
+
+// Parse utmp records, return the logged in users, one per session.
+func (si *SysInfo) readUtmp(utmpPath string) ([]string, error) {
+	data, err := si.readFile(utmpPath)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]string, 0)
+	for p := 0; p+utmpSize <= len(data); p += utmpSize {
+		if word(data, p+utmpTypeOffset) != utmpUserProcess {
+			continue
+		}
+
+		user := data[p+utmpUserOffset : p+utmpUserOffset+utmpUserSize]
+		if i := bytes.IndexByte(user, 0); i >= 0 {
+			user = user[:i]
+		}
+		if len(user) > 0 {
+			users = append(users, string(user))
+		}
+	}
+
+	return users, nil
+}
+
+// Read systemd-logind session files, return the logged in users, one per session.
+func (si *SysInfo) readLogindSessions(sessionsPath string) ([]string, error) {
+	sessions, err := si.readDir(sessionsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]string, 0)
+	for _, session := range sessions {
+		if session.IsDir() || strings.HasSuffix(session.Name(), ".ref") {
+			continue
+		}
+
+		f, err := si.open(path.Join(sessionsPath, session.Name()))
+		if err != nil {
+			continue
+		}
+
+		s := bufio.NewScanner(f)
+		for s.Scan() {
+			if sl := strings.SplitN(s.Text(), "=", 2); len(sl) == 2 && sl[0] == "USER" {
+				users = append(users, sl[1])
+				break
+			}
+		}
+		f.Close()
+	}
+
+	return users, nil
+}
+
+func (si *SysInfo) getUsers() {
+	users, err := si.readUtmp("/var/run/utmp")
+	if err != nil {
+		// Recent systems may not maintain utmp at all.
+		if users, err = si.readLogindSessions("/run/systemd/sessions"); err != nil {
+			return
+		}
+	}
+
+	si.Node.Sessions = uint(len(users))
+
+	unique := make(map[string]bool)
+	si.Node.Users = make([]string, 0)
+	for _, user := range users {
+		if !unique[user] {
+			unique[user] = true
+			si.Node.Users = append(si.Node.Users, user)
+		}
+	}
+	sort.Strings(si.Node.Users)
+}
@@ -5,22 +5,87 @@
 package sysinfo
 
 import (
-	"io/ioutil"
-	"os"
-	"strings"
+	"bytes"
+	"io"
+	"path"
+	"strconv"
+	"sync"
 )
 
+// Buffers for readOneLiner. Attribute files in sysfs are tiny, but report their size as 4096 bytes, so reading them
+// the usual way allocates a page sized buffer every time.
+var slurpPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, 0, 512)
+		return &buf
+	},
+}
+
+// Bigger buffers are not kept around, most of the files are one-liners.
+const slurpPoolMax = 64 * 1024
+
+// Log the trace line, if the logger is configured.
+func (si *SysInfo) tracef(format string, v ...interface{}) {
+	if si.Config.Logger != nil {
+		si.Config.Logger.Printf(format, v...)
+	}
+}
+
 // Read one-liner text files, strip newline.
-func slurpFile(path string) string {
-	data, err := ioutil.ReadFile(path)
+func (si *SysInfo) slurpFile(path string) string {
+	data, err := si.readOneLiner(path)
+
+	// Checked here, not to pay for the arguments escaping to interfaces, on the hot path.
+	if si.Config.Logger != nil {
+		if err != nil {
+			si.tracef("sysinfo: %v", err)
+		} else {
+			si.tracef("sysinfo: read %s: %q", path, data)
+		}
+	}
+
+	return data
+}
+
+func (si *SysInfo) readOneLiner(path string) (string, error) {
+	f, err := si.open(path)
 	if err != nil {
-		return ""
+		return "", err
+	}
+	defer f.Close()
+
+	bufp := slurpPool.Get().(*[]byte)
+	buf := (*bufp)[:0]
+	defer func() {
+		if cap(buf) <= slurpPoolMax {
+			*bufp = buf
+			slurpPool.Put(bufp)
+		}
+	}()
+
+	for {
+		if len(buf) == cap(buf) {
+			buf = append(buf, 0)[:len(buf)]
+		}
+		n, err := f.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
 	}
 
-	return strings.TrimSpace(string(data))
+	return string(bytes.TrimSpace(buf)), nil
 }
 
-// Write one-liner text files, add newline, ignore errors (best effort).
-func spewFile(path string, data string, perm os.FileMode) {
-	_ = ioutil.WriteFile(path, []byte(data+"\n"), perm)
+// Read NUMA node of a device from sysfs, nil if not known (-1) or not applicable.
+func (si *SysInfo) getNUMANode(devicePath string) *int {
+	node, err := strconv.Atoi(si.slurpFile(path.Join(devicePath, "numa_node")))
+	if err != nil || node < 0 {
+		return nil
+	}
+
+	return &node
 }
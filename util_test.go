@@ -0,0 +1,51 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSlurpFile(t *testing.T) {
+	var si SysInfo
+	dir := t.TempDir()
+
+	for _, tc := range []struct {
+		name, data, want string
+	}{
+		{"oneliner", "write back\n", "write back"},
+		{"empty", "", ""},
+		{"spaces", "  0x8086 \n\n", "0x8086"},
+		{"big", strings.Repeat("x", 3*slurpPoolMax) + "\n", strings.Repeat("x", 3*slurpPoolMax)},
+	} {
+		p := filepath.Join(dir, tc.name)
+		if err := ioutil.WriteFile(p, []byte(tc.data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if got := si.slurpFile(p); got != tc.want {
+			t.Errorf("slurpFile(%s) = %.20q (len %d), want %.20q (len %d)", tc.name, got, len(got), tc.want, len(tc.want))
+		}
+	}
+
+	if got := si.slurpFile(filepath.Join(dir, "missing")); got != "" {
+		t.Errorf("slurpFile(missing) = %q, want empty", got)
+	}
+}
+
+func BenchmarkSlurpFile(b *testing.B) {
+	p := filepath.Join(b.TempDir(), "attr")
+	if err := ioutil.WriteFile(p, []byte("write back\n"), 0644); err != nil {
+		b.Fatal(err)
+	}
+
+	var si SysInfo
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		si.slurpFile(p)
+	}
+}
@@ -6,3 +6,7 @@ package sysinfo
 
 // Version of the sysinfo library.
 const Version = "0.9.5"
+
+// SchemaVersion of the SysInfo data, bumped whenever fields are added, renamed or change their meaning, so stored
+// snapshots can be told apart and migrated.
+const SchemaVersion = 2
@@ -0,0 +1,104 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+package sysinfo
+
+import (
+	"context"
+	"time"
+)
+
+// StorageEvent describes a storage device being added to or removed from the system.
+type StorageEvent struct {
+	Action string        `json:"action" msgpack:"act"` // add or remove
+	Device StorageDevice `json:"device" msgpack:"dev"`
+}
+
+const (
+	// How often to rescan storage devices, when kernel uevents are not available.
+	storagePollInterval = 5 * time.Second
+
+	// How long to wait after a uevent before rescanning, so udev gets a chance to populate its database (serial
+	// numbers come from there), and so bursts of events (disk + partitions) are handled in one pass.
+	storageSettleDelay = time.Second
+)
+
+// WatchStorage reports storage devices as they are plugged and unplugged, until the context is canceled. Kernel
+// uevents are used to detect changes, falling back to periodic rescans where those are unavailable. Removal events
+// carry the device as it was last seen, so it can be correlated with an earlier snapshot by name or serial.
+func WatchStorage(ctx context.Context) (<-chan StorageEvent, error) {
+	var si SysInfo
+	si.getStorageInfo()
+	known := storageByName(si.Storage)
+
+	// A nil trigger means polling.
+	trigger, _ := watchStorageUevents(ctx)
+
+	events := make(chan StorageEvent)
+	go func() {
+		defer close(events)
+
+		poll := time.NewTicker(storagePollInterval)
+		defer poll.Stop()
+		if trigger != nil {
+			poll.Stop()
+		}
+
+		settle := time.NewTimer(storageSettleDelay)
+		settle.Stop()
+		defer settle.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-trigger:
+				settle.Reset(storageSettleDelay)
+				continue
+			case <-settle.C:
+			case <-poll.C:
+			}
+
+			si.getStorageInfo()
+			current := storageByName(si.Storage)
+
+			for _, device := range si.Storage {
+				if _, ok := known[device.Name]; !ok {
+					if !sendStorageEvent(ctx, events, StorageEvent{Action: "add", Device: device}) {
+						return
+					}
+				}
+			}
+			for name, device := range known {
+				if _, ok := current[name]; !ok {
+					if !sendStorageEvent(ctx, events, StorageEvent{Action: "remove", Device: device}) {
+						return
+					}
+				}
+			}
+
+			known = current
+		}
+	}()
+
+	return events, nil
+}
+
+func storageByName(devices []StorageDevice) map[string]StorageDevice {
+	byName := make(map[string]StorageDevice, len(devices))
+	for _, device := range devices {
+		byName[device.Name] = device
+	}
+
+	return byName
+}
+
+func sendStorageEvent(ctx context.Context, events chan<- StorageEvent, event StorageEvent) bool {
+	select {
+	case events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package sysinfo
+
+import (
+	"bytes"
+	"context"
+
+	"golang.org/x/sys/unix"
+)
+
+// Listen for kernel uevents of the block subsystem, signal each device addition or removal on the returned channel.
+func watchStorageUevents(ctx context.Context) (<-chan struct{}, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.NETLINK_KOBJECT_UEVENT)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: 1}); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+
+	// Wake up periodically to check if we are done.
+	tv := unix.Timeval{Sec: 1}
+	if err = unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &tv); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+
+	trigger := make(chan struct{}, 1)
+	go func() {
+		defer unix.Close(fd)
+
+		buf := make([]byte, 8192)
+		for ctx.Err() == nil {
+			n, _, err := unix.Recvfrom(fd, buf, 0)
+			if err != nil {
+				continue
+			}
+
+			// add@/devices/pci0000:00/.../block/sdb\0ACTION=add\0DEVPATH=...\0SUBSYSTEM=block\0...
+			var action, subsystem []byte
+			for _, field := range bytes.Split(buf[:n], []byte{0}) {
+				if bytes.HasPrefix(field, []byte("ACTION=")) {
+					action = field[7:]
+				} else if bytes.HasPrefix(field, []byte("SUBSYSTEM=")) {
+					subsystem = field[10:]
+				}
+			}
+			if string(subsystem) != "block" || (string(action) != "add" && string(action) != "remove") {
+				continue
+			}
+
+			select {
+			case trigger <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return trigger, nil
+}
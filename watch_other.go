@@ -0,0 +1,17 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+//go:build !linux
+// +build !linux
+
+package sysinfo
+
+import (
+	"context"
+	"errors"
+)
+
+func watchStorageUevents(ctx context.Context) (<-chan struct{}, error) {
+	return nil, errors.New("uevents not supported")
+}
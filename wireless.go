@@ -0,0 +1,148 @@
+// Copyright © 2016 Zlatko Čalušić
+//
+// Use of this source code is governed by an MIT-style license that can be found in the LICENSE file.
+
+//go:build !windows
+// +build !windows
+
+package sysinfo
+
+import (
+	"bufio"
+	"path"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// Wireless extensions ioctls, from /usr/include/linux/wireless.h
+const (
+	siocgiwfreq  = 0x8b05
+	siocgiwessid = 0x8b1b
+
+	iwEssidMaxSize = 32
+)
+
+// struct iwreq from /usr/include/linux/wireless.h, union iwreq_data is 16 bytes.
+type iwreq struct {
+	Name [16]byte
+	Data [16]byte
+}
+
+func iwIoctl(name string, req uintptr, iwr *iwreq) bool {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM, syscall.IPPROTO_IP)
+	if err != nil {
+		return false
+	}
+	defer syscall.Close(fd)
+
+	copy(iwr.Name[:], name+"\000")
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), req, uintptr(unsafe.Pointer(iwr)))
+
+	return errno == 0
+}
+
+func getSSID(name string) string {
+	var iwr iwreq
+	essid := make([]byte, iwEssidMaxSize+1)
+
+	// struct iw_point: pointer to the buffer, followed by its length.
+	*(*uintptr)(unsafe.Pointer(&iwr.Data[0])) = uintptr(unsafe.Pointer(&essid[0]))
+	*(*uint16)(unsafe.Pointer(&iwr.Data[unsafe.Sizeof(uintptr(0))])) = uint16(len(essid))
+
+	if !iwIoctl(name, siocgiwessid, &iwr) {
+		return ""
+	}
+
+	length := *(*uint16)(unsafe.Pointer(&iwr.Data[unsafe.Sizeof(uintptr(0))]))
+	if int(length) > len(essid) {
+		length = uint16(len(essid))
+	}
+
+	return strings.TrimRight(string(essid[:length]), "\000")
+}
+
+// Returns frequency in MHz, or channel number when the driver reports only that.
+func getFrequency(name string) (freq uint, channel uint) {
+	var iwr iwreq
+	if !iwIoctl(name, siocgiwfreq, &iwr) {
+		return
+	}
+
+	// struct iw_freq: value is m * 10^e Hz
+	m := int64(*(*int32)(unsafe.Pointer(&iwr.Data[0])))
+	e := int(*(*int16)(unsafe.Pointer(&iwr.Data[4])))
+
+	if e == 0 && m > 0 && m < 1000 {
+		return 0, uint(m)
+	}
+
+	for ; e > 0; e-- {
+		m *= 10
+	}
+	freq = uint(m / 1000000)
+
+	return freq, frequencyToChannel(freq)
+}
+
+func frequencyToChannel(freq uint) uint {
+	switch {
+	case freq == 2484:
+		return 14
+	case freq >= 2412 && freq < 2484:
+		return (freq - 2407) / 5
+	case freq >= 5955 && freq <= 7115:
+		return (freq - 5950) / 5
+	case freq >= 5000 && freq < 5955:
+		return (freq - 5000) / 5
+	}
+
+	return 0
+}
+
+// Signal level of the interface from /proc/net/wireless, in dBm.
+func (si *SysInfo) getSignalLevel(name string) int {
+	f, err := si.open("/proc/net/wireless")
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 4 || fields[0] != name+":" {
+			continue
+		}
+		level, err := strconv.ParseFloat(strings.TrimSuffix(fields[3], "."), 64)
+		if err != nil {
+			return 0
+		}
+		return int(level)
+	}
+
+	return 0
+}
+
+// Signal level is read from procfs, SSID & frequency need ioctls, skipped when unprivileged.
+func (si *SysInfo) getWireless(fullpath string) *WirelessInfo {
+	_, err := si.stat(path.Join(fullpath, "wireless"))
+	if err != nil {
+		if _, err = si.stat(path.Join(fullpath, "phy80211")); err != nil {
+			return nil
+		}
+	}
+
+	name := path.Base(fullpath)
+	wireless := &WirelessInfo{
+		Signal: si.getSignalLevel(name),
+	}
+	if !si.Config.Unprivileged {
+		wireless.SSID = getSSID(name)
+		wireless.Frequency, wireless.Channel = getFrequency(name)
+	}
+
+	return wireless
+}